@@ -0,0 +1,251 @@
+package motecldparser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// NewChannel builds a channel of the type matching data, which must be a
+// []float32, []int16 or []int32.
+//
+// It is meant for call sites where the sample type is only known at run time.
+// The returned channel refers to data directly, without copying it. Returns
+// ErrUnsupportedDataType for any other type of data.
+//
+// Example:
+//
+//	channel, err := motecldparser.NewChannel("Gear", "", 10, []int16{1, 2, 2, 3})
+func NewChannel(name, unit string, freq uint16, data any) (AnyChannel, error) {
+	return ChannelTemplate{Frequency: freq, Name: name, Unit: unit}.Channel(data)
+}
+
+// ChannelTemplate describes a channel without its data.
+//
+// Templates let a batch of files share the same channel definitions: the
+// names, units and frequencies are declared once and each file only provides
+// the data (see FromTemplates).
+type ChannelTemplate struct {
+	Frequency uint16 // Sampling frequency in Hz
+	Name      string // Full channel name
+	ShortName string // Abbreviated name (displayed in compact views)
+	Unit      string // Unit of measurement
+}
+
+// Channel builds a channel described by the template, holding data.
+//
+// The type of the channel matches data, which must be a []float32, []int16 or
+// []int32; see NewChannel.
+func (t ChannelTemplate) Channel(data any) (AnyChannel, error) {
+	switch d := data.(type) {
+	case []float32:
+		return &Channel[float32]{Frequency: t.Frequency, Name: t.Name, ShortName: t.ShortName, Unit: t.Unit, Data: &d}, nil
+	case []int16:
+		return &Channel[int16]{Frequency: t.Frequency, Name: t.Name, ShortName: t.ShortName, Unit: t.Unit, Data: &d}, nil
+	case []int32:
+		return &Channel[int32]{Frequency: t.Frequency, Name: t.Name, ShortName: t.ShortName, Unit: t.Unit, Data: &d}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedDataType, data)
+	}
+}
+
+// FromTemplates builds a File with one channel per template, in order, where
+// the i-th channel holds data[i].
+//
+// Each data value must be a []float32, []int16 or []int32. Session metadata is
+// left empty for the caller to fill in.
+//
+// Example:
+//
+//	templates := []motecldparser.ChannelTemplate{
+//	    {Frequency: 100, Name: "Speed", ShortName: "SPD", Unit: "km/h"},
+//	    {Frequency: 10, Name: "Gear", ShortName: "GEAR"},
+//	}
+//	for _, session := range sessions {
+//	    file, err := motecldparser.FromTemplates(templates, session.Speed, session.Gear)
+//	    ...
+//	}
+func FromTemplates(templates []ChannelTemplate, data ...any) (*File, error) {
+	if len(data) != len(templates) {
+		return nil, fmt.Errorf("motecldparser: %d data slices for %d channel templates", len(data), len(templates))
+	}
+
+	f := &File{}
+	for i, t := range templates {
+		channel, err := t.Channel(data[i])
+		if err != nil {
+			return nil, fmt.Errorf("channel %d (%q): %w", i, t.Name, err)
+		}
+		f.AddChannels(channel)
+	}
+
+	return f, nil
+}
+
+// FromSeries builds a File with one float32 channel per named series.
+//
+// All channels are sampled at freq and take their unit from units, if
+// present. Channels are added in alphabetical order of their names so the
+// result is deterministic.
+//
+// All channels of the returned file have the same length, the length of the
+// longest series. Shorter series are padded by repeating their last value, or
+// with zeros if they are empty. Session metadata (time, driver, ...) is left
+// empty for the caller to fill in.
+//
+// Example:
+//
+//	file := motecldparser.FromSeries(100, map[string][]float64{
+//	    "Speed":    speed,
+//	    "Throttle": throttle,
+//	}, map[string]string{"Speed": "km/h", "Throttle": "%"})
+func FromSeries(freq uint16, series map[string][]float64, units map[string]string) *File {
+	names := make([]string, 0, len(series))
+	length := 0
+	for name, values := range series {
+		names = append(names, name)
+		length = max(length, len(values))
+	}
+	sort.Strings(names)
+
+	f := &File{}
+	for _, name := range names {
+		values := series[name]
+
+		data := make([]float32, length)
+		for i := range data {
+			switch {
+			case i < len(values):
+				data[i] = float32(values[i])
+			case len(values) > 0:
+				data[i] = float32(values[len(values)-1])
+			}
+		}
+
+		f.AddChannels(&Channel[float32]{
+			Frequency: freq,
+			Name:      name,
+			Unit:      units[name],
+			Data:      &data,
+		})
+	}
+
+	return f
+}
+
+// FromInterleaved builds a File from row-major samples, one float32 channel
+// per name.
+//
+// Each row holds one sample of every channel at a given time, in the order of
+// names, as produced by many real-time loggers. The LD format stores each
+// channel contiguously, so the rows are transposed into one channel per
+// column, all sampled at freq. Values missing from a short row are stored as
+// NaN and extra values are ignored. Session metadata is left empty for the
+// caller to fill in.
+//
+// Example:
+//
+//	file := motecldparser.FromInterleaved(50, []string{"Speed", "RPM"}, [][]float64{
+//	    {120.5, 7200},
+//	    {121.0, 7250},
+//	})
+func FromInterleaved(freq uint16, names []string, rows [][]float64) *File {
+	f := &File{}
+	for column, name := range names {
+		data := make([]float32, len(rows))
+		for i, row := range rows {
+			if column < len(row) {
+				data[i] = float32(row[column])
+			} else {
+				data[i] = float32(math.NaN())
+			}
+		}
+
+		f.AddChannels(&Channel[float32]{
+			Frequency: freq,
+			Name:      name,
+			Data:      &data,
+		})
+	}
+
+	return f
+}
+
+// FromTimeSeries builds a float32 channel sampled at targetHz from samples
+// taken at irregular times.
+//
+// The LD format only stores fixed-rate channels, so the (times[i], values[i])
+// pairs are resampled onto a uniform grid starting at the earliest time and
+// ending at or before the latest one. Each grid sample is linearly
+// interpolated between the two samples surrounding it; gaps between distant
+// samples are bridged the same way, and nothing is extrapolated. The pairs
+// need not be sorted. If several samples share a time, the last one in
+// times wins. Extra elements of the longer slice are ignored, and an empty
+// channel is returned if there are no samples or targetHz is zero.
+//
+// The channel starts at the earliest time, which is typically also used as
+// File.Time.
+//
+// Example:
+//
+//	speed := motecldparser.FromTimeSeries("Speed", "km/h", 50, timestamps, readings)
+func FromTimeSeries(name, unit string, targetHz uint16, times []time.Time, values []float64) *Channel[float32] {
+	n := min(len(times), len(values))
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return times[order[a]].Before(times[order[b]])
+	})
+
+	// Keep one sample per time, the last one given
+	var pointTimes []time.Time
+	var pointValues []float64
+	for _, i := range order {
+		if last := len(pointTimes) - 1; last >= 0 && times[i].Equal(pointTimes[last]) {
+			pointValues[last] = values[i]
+			continue
+		}
+		pointTimes = append(pointTimes, times[i])
+		pointValues = append(pointValues, values[i])
+	}
+
+	data := make([]float32, 0)
+	if len(pointTimes) > 0 && targetHz > 0 {
+		// Count the grid points with integer math: converting the span to
+		// float seconds can round an exact multiple of the period down and
+		// lose the last point. Whole seconds are scaled apart so the product
+		// cannot overflow on long sessions.
+		start, end := pointTimes[0], pointTimes[len(pointTimes)-1]
+		span, hz := end.Sub(start), time.Duration(targetHz)
+		data = make([]float32, int(span/time.Second*hz+span%time.Second*hz/time.Second)+1)
+
+		right := 0
+		for i := range data {
+			at := start.Add(time.Duration(i) * time.Second / hz)
+
+			// Move to the first sample at or after the grid time
+			for right < len(pointTimes)-1 && pointTimes[right].Before(at) {
+				right++
+			}
+
+			if right == 0 || !pointTimes[right].After(at) {
+				data[i] = float32(pointValues[right])
+				continue
+			}
+
+			left := right - 1
+			weight := at.Sub(pointTimes[left]).Seconds() / pointTimes[right].Sub(pointTimes[left]).Seconds()
+			data[i] = float32(pointValues[left]*(1-weight) + pointValues[right]*weight)
+		}
+	}
+
+	return &Channel[float32]{
+		Frequency: targetHz,
+		Name:      name,
+		Unit:      unit,
+		Data:      &data,
+	}
+}
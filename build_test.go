@@ -0,0 +1,148 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewChannel(t *testing.T) {
+	tests := []struct {
+		data     any
+		wantType string
+		wantErr  error
+	}{
+		{data: []float32{1.5}, wantType: "*motecldparser.Channel[float32]"},
+		{data: []int16{1}, wantType: "*motecldparser.Channel[int16]"},
+		{data: []int32{1}, wantType: "*motecldparser.Channel[int32]"},
+		{data: []float64{1}, wantErr: ErrUnsupportedDataType},
+		{data: nil, wantErr: ErrUnsupportedDataType},
+	}
+
+	for _, tt := range tests {
+		c, err := NewChannel("Name", "unit", 10, tt.data)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%T: got error %v, want %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := fmt.Sprintf("%T", c); got != tt.wantType {
+			t.Errorf("%T: got %s, want %s", tt.data, got, tt.wantType)
+		}
+		if c.ChannelName() != "Name" || c.ChannelUnit() != "unit" || c.ChannelFrequency() != 10 || c.SampleCount() != 1 {
+			t.Errorf("%T: got %+v", tt.data, c)
+		}
+	}
+}
+
+func TestFromTemplates(t *testing.T) {
+	templates := []ChannelTemplate{
+		{Frequency: 100, Name: "Speed", ShortName: "SPD", Unit: "km/h"},
+		{Frequency: 10, Name: "Gear", ShortName: "GEAR"},
+	}
+
+	f, err := FromTemplates(templates, []float32{1, 2}, []int16{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos := f.ChannelInfos()
+	if len(infos) != 2 || infos[0].ShortName != "SPD" || infos[1].Name != "Gear" || infos[1].Samples != 1 {
+		t.Errorf("got %+v", infos)
+	}
+
+	if _, err := FromTemplates(templates, []float32{1}); err == nil {
+		t.Error("missing data: got nil error")
+	}
+	if _, err := FromTemplates(templates, []float32{1}, []string{"a"}); !errors.Is(err, ErrUnsupportedDataType) {
+		t.Errorf("unsupported data: got %v", err)
+	}
+}
+
+func TestFromSeries(t *testing.T) {
+	f := FromSeries(10, map[string][]float64{
+		"Throttle": {0, 50, 100},
+		"Speed":    {10},
+		"Empty":    {},
+	}, map[string]string{"Speed": "km/h"})
+
+	want := map[string][]float32{
+		"Empty":    {0, 0, 0},
+		"Speed":    {10, 10, 10},
+		"Throttle": {0, 50, 100},
+	}
+	var names []string
+	for _, channel := range f.Channels {
+		c := channel.(*Channel[float32])
+		names = append(names, c.Name)
+		if !slices.Equal(*c.Data, want[c.Name]) {
+			t.Errorf("%s = %v, want %v", c.Name, *c.Data, want[c.Name])
+		}
+	}
+	if !slices.Equal(names, []string{"Empty", "Speed", "Throttle"}) {
+		t.Errorf("channels = %v, want sorted names", names)
+	}
+	if unit := f.Channels[1].(*Channel[float32]).Unit; unit != "km/h" {
+		t.Errorf("unit = %q", unit)
+	}
+}
+
+func TestFromInterleaved(t *testing.T) {
+	f := FromInterleaved(50, []string{"Speed", "RPM"}, [][]float64{
+		{120.5, 7200},
+		{121, 7250, 99},
+		{122},
+	})
+
+	speed := *f.Channels[0].(*Channel[float32]).Data
+	if !slices.Equal(speed, []float32{120.5, 121, 122}) {
+		t.Errorf("speed = %v", speed)
+	}
+	rpm := *f.Channels[1].(*Channel[float32]).Data
+	if rpm[0] != 7200 || rpm[1] != 7250 || !math.IsNaN(float64(rpm[2])) {
+		t.Errorf("rpm = %v, want a NaN for the short row", rpm)
+	}
+}
+
+func TestFromTimeSeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name   string
+		hz     uint16
+		times  []time.Time
+		values []float64
+		want   []float32
+	}{
+		{name: "regular", hz: 10, times: []time.Time{at(0), at(100), at(200)}, values: []float64{0, 1, 2}, want: []float32{0, 1, 2}},
+		{name: "interpolated", hz: 10, times: []time.Time{at(0), at(300)}, values: []float64{0, 3}, want: []float32{0, 1, 2, 3}},
+		{name: "unsorted", hz: 10, times: []time.Time{at(200), at(0), at(100)}, values: []float64{2, 0, 1}, want: []float32{0, 1, 2}},
+		{name: "duplicate time keeps the last", hz: 10, times: []time.Time{at(0), at(100), at(100)}, values: []float64{0, 5, 1}, want: []float32{0, 1}},
+		{name: "span a multiple of the period", hz: 100, times: []time.Time{at(0), at(290)}, values: []float64{0, 29}, want: []float32{
+			0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14,
+			15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29,
+		}},
+		{name: "3 Hz", hz: 3, times: []time.Time{at(0), at(2000)}, values: []float64{0, 6}, want: []float32{0, 1, 2, 3, 4, 5, 6}},
+		{name: "no extrapolation", hz: 10, times: []time.Time{at(0), at(150)}, values: []float64{0, 3}, want: []float32{0, 2}},
+		{name: "extra values ignored", hz: 10, times: []time.Time{at(0)}, values: []float64{4, 5}, want: []float32{4}},
+		{name: "no samples", hz: 10, want: []float32{}},
+		{name: "zero frequency", hz: 0, times: []time.Time{at(0)}, values: []float64{1}, want: []float32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := FromTimeSeries("Speed", "km/h", tt.hz, tt.times, tt.values)
+			if !slices.Equal(*c.Data, tt.want) {
+				t.Errorf("got %v, want %v", *c.Data, tt.want)
+			}
+			if c.Name != "Speed" || c.Unit != "km/h" || c.Frequency != tt.hz {
+				t.Errorf("channel = %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RenameChannel renames every channel named oldName to newName.
+//
+// Returns false, leaving the file unchanged, if no channel is named oldName
+// or if newName does not fit the 32-byte name field.
+func (f *File) RenameChannel(oldName, newName string) bool {
+	if len(newName) > 32 {
+		return false
+	}
+
+	renamed := false
+	for _, c := range f.AnyChannels() {
+		if c.ChannelName() == oldName {
+			c.setName(newName)
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+// ApplyNameMap renames channels according to a map from old to new names.
+//
+// This is typically used when importing data from non-MoTeC sources, e.g.
+// mapping "ground_speed" to "Ground Speed". Names are looked up in the map
+// using the channel names before any renaming, so swapping two names works as
+// expected. Channels whose name is not in the map are left unchanged.
+//
+// New names that do not fit the 32-byte name field are not applied. They are
+// reported as *FieldError values wrapping ErrStringTooLong, joined with
+// errors.Join.
+func (f *File) ApplyNameMap(m map[string]string) error {
+	var errs []error
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		newName, ok := m[c.ChannelName()]
+		if !ok {
+			continue
+		}
+
+		if err := checkLength(i, "Name", newName, 32); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		c.setName(newName)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Channel[T]) setName(name string) {
+	c.Name = name
+}
+
+func (c *StreamChannel[T]) setName(name string) {
+	c.Name = name
+}
+
+// UnitAliases maps common spellings of units to the strings used by MoTeC i2.
+//
+// It is the mapping used by File.NormalizeUnits when none is given. Entries
+// can be added for project-specific spellings.
+var UnitAliases = map[string]string{
+	// Speed and acceleration
+	"kph": "km/h", "kmh": "km/h", "KPH": "km/h", "km/hr": "km/h", "MPH": "mph",
+	"m/s^2": "m/s/s", "m/s2": "m/s/s",
+	// Rotation and angles
+	"RPM": "rpm", "r/min": "rpm", "degrees": "deg", "°": "deg",
+	// Temperature
+	"deg C": "°C", "degC": "°C", "deg F": "°F", "degF": "°F",
+	// Pressure
+	"kpa": "kPa", "KPA": "kPa", "Bar": "bar", "PSI": "psi",
+	// Electrical
+	"v": "V", "volts": "V", "amps": "A",
+	// Time
+	"sec": "s", "secs": "s", "msec": "ms",
+	// Dimensionless
+	"percent": "%", "pct": "%",
+}
+
+// NormalizeUnits replaces unit aliases with the unit strings used by MoTeC i2.
+//
+// Each channel whose unit is a key of mapping gets the corresponding value as
+// its new unit; other units are left untouched. A nil mapping uses
+// UnitAliases. Consistent units let i2 convert units in math channels, so this
+// is best called right before writing. Remaining unknown units can be listed
+// with CheckUnits.
+func (f *File) NormalizeUnits(mapping map[string]string) {
+	if mapping == nil {
+		mapping = UnitAliases
+	}
+
+	for _, c := range f.AnyChannels() {
+		if unit, ok := mapping[c.ChannelUnit()]; ok {
+			c.setUnit(unit)
+		}
+	}
+}
+
+func (c *Channel[T]) setUnit(unit string) {
+	c.Unit = unit
+}
+
+func (c *StreamChannel[T]) setUnit(unit string) {
+	c.Unit = unit
+}
+
+// DedupeStrategy selects how File.DedupeChannels resolves channels sharing the
+// same name.
+type DedupeStrategy int
+
+const (
+	// DedupeError leaves the file unchanged and reports every duplicate.
+	DedupeError DedupeStrategy = iota
+
+	// DedupeKeepFirst keeps the first channel with each name and removes the
+	// others.
+	DedupeKeepFirst
+
+	// DedupeConcatSameFrequency appends the data of every duplicate, in order,
+	// to the first channel with the same name and removes the duplicates. All
+	// channels sharing a name must have the same data type and frequency.
+	DedupeConcatSameFrequency
+)
+
+// DedupeChannels resolves channels sharing the same name using the given
+// strategy.
+//
+// Duplicates typically appear after importing data from several sources.
+// Resolving them before writing avoids ambiguous channels in i2. The file is
+// only modified if no error is returned. Errors are *FieldError values
+// wrapping ErrDuplicateName, joined with errors.Join. A strategy other than
+// the DedupeStrategy constants is rejected without modifying the file.
+func (f *File) DedupeChannels(strategy DedupeStrategy) error {
+	switch strategy {
+	case DedupeError, DedupeKeepFirst, DedupeConcatSameFrequency:
+	default:
+		return fmt.Errorf("motecldparser: unknown dedupe strategy %d", int(strategy))
+	}
+
+	first := make(map[string]int)
+	var duplicates []int
+	var errs []error
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		name := c.ChannelName()
+		j, ok := first[name]
+		if !ok {
+			first[name] = i
+			continue
+		}
+
+		duplicates = append(duplicates, i)
+		switch strategy {
+		case DedupeError:
+			errs = append(errs, &FieldError{Channel: i, Field: "Name", Reason: fmt.Sprintf("%q is also used by channel %d", name, j), Err: ErrDuplicateName})
+		case DedupeConcatSameFrequency:
+			if !canConcat(f.Channels[j], channel) {
+				errs = append(errs, &FieldError{Channel: i, Field: "Name", Reason: fmt.Sprintf("cannot be concatenated to channel %d: different data type or frequency", j), Err: ErrDuplicateName})
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if strategy == DedupeConcatSameFrequency {
+		for _, i := range duplicates {
+			concat(f.Channels[first[f.Channels[i].(AnyChannel).ChannelName()]], f.Channels[i])
+		}
+	}
+
+	// Remove the duplicates
+	for k := len(duplicates) - 1; k >= 0; k-- {
+		i := duplicates[k]
+		f.Channels = append(f.Channels[:i], f.Channels[i+1:]...)
+	}
+
+	return nil
+}
+
+// canConcat reports whether the data of src can be appended to dst.
+func canConcat(dst, src any) bool {
+	switch d := dst.(type) {
+	case *Channel[float32]:
+		s, ok := src.(*Channel[float32])
+		return ok && d.Frequency == s.Frequency
+	case *Channel[int16]:
+		s, ok := src.(*Channel[int16])
+		return ok && d.Frequency == s.Frequency
+	case *Channel[int32]:
+		s, ok := src.(*Channel[int32])
+		return ok && d.Frequency == s.Frequency
+	default:
+		return false
+	}
+}
+
+// concat appends the data of src to dst. Both must pass canConcat.
+func concat(dst, src any) {
+	switch d := dst.(type) {
+	case *Channel[float32]:
+		concatData(d, src.(*Channel[float32]))
+	case *Channel[int16]:
+		concatData(d, src.(*Channel[int16]))
+	case *Channel[int32]:
+		concatData(d, src.(*Channel[int32]))
+	}
+}
+
+// concatData replaces the data of dst with a new slice holding the data of
+// dst followed by the data of src, leaving the original slices untouched.
+func concatData[T float32 | int16 | int32](dst, src *Channel[T]) {
+	data := make([]T, 0, dst.SampleCount()+src.SampleCount())
+	if dst.Data != nil {
+		data = append(data, *dst.Data...)
+	}
+	if src.Data != nil {
+		data = append(data, *src.Data...)
+	}
+	dst.Data = &data
+}
@@ -0,0 +1,114 @@
+package motecldparser
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// duplicatedFile returns a file with two float32 channels named "Speed" and
+// one named "RPM".
+func duplicatedFile() *File {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{1, 2}},
+		&Channel[float32]{Frequency: 10, Name: "RPM", Data: &[]float32{100}},
+		&Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{3}},
+	)
+	return f
+}
+
+func TestDedupeChannels(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  DedupeStrategy
+		wantErr   error
+		wantNames []string
+		wantSpeed []float32
+	}{
+		{name: "error", strategy: DedupeError, wantErr: ErrDuplicateName, wantNames: []string{"Speed", "RPM", "Speed"}, wantSpeed: []float32{1, 2}},
+		{name: "keep first", strategy: DedupeKeepFirst, wantNames: []string{"Speed", "RPM"}, wantSpeed: []float32{1, 2}},
+		{name: "concatenate", strategy: DedupeConcatSameFrequency, wantNames: []string{"Speed", "RPM"}, wantSpeed: []float32{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := duplicatedFile()
+			if err := f.DedupeChannels(tt.strategy); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			var names []string
+			for _, c := range f.AnyChannels() {
+				names = append(names, c.ChannelName())
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("channels = %v, want %v", names, tt.wantNames)
+			}
+			if speed := *f.Channels[0].(*Channel[float32]).Data; !slices.Equal(speed, tt.wantSpeed) {
+				t.Errorf("speed = %v, want %v", speed, tt.wantSpeed)
+			}
+		})
+	}
+}
+
+func TestDedupeChannelsUnknownStrategy(t *testing.T) {
+	f := duplicatedFile()
+	if err := f.DedupeChannels(DedupeStrategy(42)); err == nil {
+		t.Fatal("got nil error")
+	}
+	if len(f.Channels) != 3 {
+		t.Errorf("file modified: %d channels", len(f.Channels))
+	}
+}
+
+func TestRenameChannel(t *testing.T) {
+	f := duplicatedFile()
+	if !f.RenameChannel("RPM", "Engine Speed") {
+		t.Fatal("RenameChannel returned false")
+	}
+	if f.RenameChannel("Missing", "Other") {
+		t.Error("renamed a missing channel")
+	}
+	if name := f.Channels[1].(*Channel[float32]).Name; name != "Engine Speed" {
+		t.Errorf("name = %q", name)
+	}
+}
+
+func TestApplyNameMap(t *testing.T) {
+	f := duplicatedFile()
+	err := f.ApplyNameMap(map[string]string{
+		"Speed": "RPM",
+		"RPM":   strings.Repeat("x", 33),
+	})
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Channel != 1 || !errors.Is(err, ErrStringTooLong) {
+		t.Errorf("got %v, want a too long name on channel 1", err)
+	}
+
+	var names []string
+	for _, c := range f.AnyChannels() {
+		names = append(names, c.ChannelName())
+	}
+	if want := []string{"RPM", "RPM", "RPM"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestNormalizeUnits(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", Unit: "kph"},
+		&Channel[float32]{Frequency: 10, Name: "Other", Unit: "custom"},
+	)
+	f.NormalizeUnits(map[string]string{"kph": "km/h"})
+
+	if unit := f.Channels[0].(*Channel[float32]).Unit; unit != "km/h" {
+		t.Errorf("alias not replaced: %q", unit)
+	}
+	if unit := f.Channels[1].(*Channel[float32]).Unit; unit != "custom" {
+		t.Errorf("unknown unit modified: %q", unit)
+	}
+}
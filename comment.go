@@ -0,0 +1,74 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EncodeCommentKV formats key-value pairs as an event comment, giving a
+// convention for storing structured metadata (tire set, fuel, setup ID...) in
+// File.EventComment.
+//
+// Each pair is written on its own line as "key=value", sorted by key, so the
+// comment stays readable in i2. Keys must be non-empty and may not contain
+// '=' or line breaks; values may not contain line breaks. Leading and
+// trailing spaces are not preserved.
+//
+// Returns ErrStringTooLong if the result does not fit the 1024 bytes of
+// EventComment.
+//
+// Example:
+//
+//	comment, err := motecldparser.EncodeCommentKV(map[string]string{
+//	    "tires": "set 3",
+//	    "fuel":  "45 l",
+//	})
+//	file.EventComment = comment // "fuel=45 l\ntires=set 3"
+func EncodeCommentKV(kv map[string]string) (string, error) {
+	keys := make([]string, 0, len(kv))
+	for key := range kv {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		value := kv[key]
+		switch {
+		case strings.TrimSpace(key) == "":
+			return "", errors.New("motecldparser: empty comment key")
+		case strings.ContainsAny(key, "=\r\n"):
+			return "", fmt.Errorf("motecldparser: comment key %q contains '=' or a line break", key)
+		case strings.ContainsAny(value, "\r\n"):
+			return "", fmt.Errorf("motecldparser: comment value of %q contains a line break", key)
+		}
+		lines[i] = strings.TrimSpace(key) + "=" + strings.TrimSpace(value)
+	}
+
+	comment := strings.Join(lines, "\n")
+	if len(comment) > 1024 {
+		return "", fmt.Errorf("%w: event comment of %d bytes, max 1024", ErrStringTooLong, len(comment))
+	}
+
+	return comment, nil
+}
+
+// DecodeCommentKV parses the key-value pairs of a comment written by
+// EncodeCommentKV.
+//
+// Lines without '=' are ignored, so comments mixing pairs with free text can
+// be decoded. If a key appears more than once, the last value wins.
+func DecodeCommentKV(comment string) map[string]string {
+	kv := make(map[string]string)
+	for _, line := range strings.Split(comment, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		kv[key] = strings.TrimSpace(value)
+	}
+	return kv
+}
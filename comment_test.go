@@ -0,0 +1,58 @@
+package motecldparser
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCommentKV(t *testing.T) {
+	tests := []struct {
+		name    string
+		kv      map[string]string
+		want    string
+		fail    bool
+		wantErr error
+	}{
+		{name: "sorted", kv: map[string]string{"tires": "set 3", "fuel": "45 l"}, want: "fuel=45 l\ntires=set 3"},
+		{name: "trimmed", kv: map[string]string{" setup ": " B2 "}, want: "setup=B2"},
+		{name: "empty", kv: map[string]string{}, want: ""},
+		{name: "empty key", kv: map[string]string{" ": "x"}, fail: true},
+		{name: "key with equals", kv: map[string]string{"a=b": "x"}, fail: true},
+		{name: "key with line break", kv: map[string]string{"a\nb": "x"}, fail: true},
+		{name: "value with line break", kv: map[string]string{"a": "x\r\ny"}, fail: true},
+		{name: "too long", kv: map[string]string{"notes": strings.Repeat("x", 1024)}, fail: true, wantErr: ErrStringTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeCommentKV(tt.kv)
+			if tt.fail != (err != nil) {
+				t.Fatalf("got error %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCommentKV(t *testing.T) {
+	kv := map[string]string{"tires": "set 3", "fuel": "45 l", "setup": "B=2"}
+	comment, err := EncodeCommentKV(kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DecodeCommentKV(comment); !maps.Equal(got, kv) {
+		t.Errorf("round trip: got %v, want %v", got, kv)
+	}
+
+	got := DecodeCommentKV("free text\n=orphan\n fuel = 40 l \nfuel=45 l")
+	if want := map[string]string{"fuel": "45 l"}; !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
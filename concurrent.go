@@ -0,0 +1,83 @@
+package motecldparser
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// WriteConcurrent writes the file to w, writing several channels at once.
+//
+// The offset of every channel is known before anything is written (see
+// File.Plan), so each channel is written independently with WriteAt to its
+// own region, without seeking. Up to File.Concurrency channels are written at
+// the same time, or runtime.GOMAXPROCS(0) if Concurrency is zero or negative.
+// The session metadata is written first. An *os.File can be written
+// concurrently this way as the regions never overlap.
+//
+// The result is byte-for-byte identical to File.Write. Stream channels are not
+// supported, as their size is only known once written: ErrUnknownSize is
+// returned and nothing is written. If several channels fail, the error of the
+// first one in File.Channels is returned.
+//
+// Example:
+//
+//	file.Concurrency = 8
+//	if err := file.WriteConcurrent(fd); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *File) WriteConcurrent(w io.WriterAt) error {
+	plan, err := f.Plan()
+	if err != nil {
+		return err
+	}
+
+	if err := f.beforeWrite(); err != nil {
+		return err
+	}
+
+	if err := f.writeSections(io.NewOffsetWriter(w, 0), plan); err != nil {
+		return err
+	}
+
+	workers := f.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	channelsCount := uint32(len(f.Channels))
+	errs := make([]error, len(f.Channels))
+	semaphore := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, c AnyChannel) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// Each channel gets its own writer, as the seek position is not shared
+			ws := io.NewOffsetWriter(w, 0)
+			if _, err := c.write(ws, uint16(i), channelsCount, uintptr(plan.ChannelsMetaPointer), uintptr(plan.Channels[i].DataPointer)); err != nil {
+				errs[i] = fmt.Errorf("write channel %d: %w", i, err)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	f.LastWritePlan = plan
+	return nil
+}
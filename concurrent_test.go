@@ -0,0 +1,68 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWriteConcurrent(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 4} {
+		f := benchFile(10, 100)
+		f.Concurrency = concurrency
+
+		// Sized up front, so concurrent writes never grow it
+		size, err := f.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := make(memWriterAt, size)
+		if err := f.WriteConcurrent(&dst); err != nil {
+			t.Fatalf("concurrency %d: %v", concurrency, err)
+		}
+		if !bytes.Equal(dst, writeBytes(t, f)) {
+			t.Errorf("concurrency %d: output differs from WriteTo", concurrency)
+		}
+		if f.LastWritePlan == nil {
+			t.Errorf("concurrency %d: LastWritePlan not set", concurrency)
+		}
+	}
+}
+
+func TestWriteConcurrentStream(t *testing.T) {
+	f := twoChannelFile()
+	f.AddChannels(&StreamChannel[float32]{Frequency: 10, Name: "Stream", Reader: strings.NewReader("")})
+
+	var dst memWriterAt
+	if err := f.WriteConcurrent(&dst); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("got %v, want ErrUnknownSize", err)
+	}
+	if len(dst) != 0 {
+		t.Errorf("wrote %d bytes", len(dst))
+	}
+}
+
+// BenchmarkWriteConcurrent compares writing the channels one at a time with
+// writing them in parallel.
+func BenchmarkWriteConcurrent(b *testing.B) {
+	f := benchFile(100, 100_000)
+	size, err := f.Size()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dst := make(memWriterAt, size)
+
+	for _, concurrency := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			f.Concurrency = concurrency
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				if err := f.WriteConcurrent(&dst); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package motecldparser
+
+import (
+	"context"
+	"io"
+)
+
+// contextReadChunk is the largest read issued by ReadContext between two
+// checks of its context.
+const contextReadChunk = 1 << 20
+
+// ReadContext parses a MoTeC LD file like Read, stopping early if ctx is
+// canceled.
+//
+// The context is checked before every read from r, and channel data is read
+// in chunks of at most 1 MiB, so parsing stops soon after cancellation even
+// in the middle of a large channel. The returned error then wraps ctx.Err().
+//
+// Example:
+//
+//	// Stop parsing an upload when the client disconnects
+//	file, err := motecldparser.ReadContext(req.Context(), upload)
+func ReadContext(ctx context.Context, r io.ReaderAt) (*File, error) {
+	return Read(contextReaderAt{ctx: ctx, r: r})
+}
+
+// contextReaderAt is an io.ReaderAt that fails with the error of its context
+// once the context is done.
+type contextReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (c contextReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		if err := c.ctx.Err(); err != nil {
+			return n, err
+		}
+
+		end := min(len(p), n+contextReadChunk)
+		m, err := c.r.ReadAt(p[n:end], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
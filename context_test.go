@@ -0,0 +1,63 @@
+package motecldparser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// cancelingReader cancels a context once a number of bytes has been read.
+type cancelingReader struct {
+	r      io.ReaderAt
+	after  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (c *cancelingReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += n
+	if c.read >= c.after {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestReadContext(t *testing.T) {
+	data := writeBytes(t, twoChannelFile())
+
+	read, err := ReadContext(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Channels) != 2 {
+		t.Errorf("got %d channels, want 2", len(read.Channels))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadContext(ctx, bytes.NewReader(data)); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: got %v, want context.Canceled", err)
+	}
+}
+
+func TestReadContextLargeChannel(t *testing.T) {
+	samples := make([]int32, 3*contextReadChunk/4) // 3 chunks of data
+	f := &File{}
+	f.AddChannels(&Channel[int32]{Frequency: 100, Name: "Large", Data: &samples})
+	data := writeBytes(t, f)
+
+	// Cancel once the first chunk of channel data has been read
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelingReader{r: bytes.NewReader(data), after: int(f.LastWritePlan.ChannelsDataPointer) + contextReadChunk, cancel: cancel}
+
+	if _, err := ReadContext(ctx, r); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if r.read >= len(data) {
+		t.Errorf("read all %d bytes before stopping", r.read)
+	}
+}
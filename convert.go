@@ -0,0 +1,100 @@
+package motecldparser
+
+import "math"
+
+// Int16ToFloat32 converts an int16 channel to a float32 channel.
+//
+// Every sample is multiplied by scale, so a raw integer channel can be
+// presented in engineering units. Name, ShortName, Unit and Frequency are
+// preserved. The original channel is not modified.
+//
+// Example:
+//
+//	temperature := Int16ToFloat32(rawTemperature, 0.1) // raw is in tenths of a degree
+func Int16ToFloat32(c *Channel[int16], scale float32) *Channel[float32] {
+	data := make([]float32, c.SampleCount())
+	for i := range data {
+		data[i] = float32((*c.Data)[i]) * scale
+	}
+
+	return &Channel[float32]{
+		Frequency: c.Frequency,
+		Name:      c.Name,
+		ShortName: c.ShortName,
+		Unit:      c.Unit,
+		Data:      &data,
+	}
+}
+
+// Float32ToInt16 quantizes a float32 channel to an int16 channel.
+//
+// Every sample is divided by scale and rounded to the nearest integer, so
+// Int16ToFloat32 with the same scale approximately restores the original
+// values. Samples outside the int16 range are clamped to the nearest bound and
+// NaN samples are stored as 0. Name, ShortName, Unit and Frequency are
+// preserved. The original channel is not modified.
+//
+// Returns the converted channel and the number of samples that were clamped
+// or were NaN.
+func Float32ToInt16(c *Channel[float32], scale float32) (*Channel[int16], int) {
+	clamped := 0
+	data := make([]int16, c.SampleCount())
+	for i := range data {
+		var ok bool
+		if data[i], ok = roundToInt16(float64((*c.Data)[i]) / float64(scale)); !ok {
+			clamped++
+		}
+	}
+
+	return &Channel[int16]{
+		Frequency: c.Frequency,
+		Name:      c.Name,
+		ShortName: c.ShortName,
+		Unit:      c.Unit,
+		Data:      &data,
+	}, clamped
+}
+
+// roundToInt16 rounds v to the nearest int16, clamping it to the int16 range
+// and mapping NaN to 0. Returns false if v was clamped or NaN.
+func roundToInt16(v float64) (int16, bool) {
+	raw := math.Round(v)
+	switch {
+	case math.IsNaN(raw):
+		return 0, false
+	case raw > math.MaxInt16:
+		return math.MaxInt16, false
+	case raw < math.MinInt16:
+		return math.MinInt16, false
+	default:
+		return int16(raw), true
+	}
+}
+
+// float64Samples returns the samples of a channel converted to float64.
+//
+// It returns false for channels whose data is not held in memory.
+func float64Samples(channel any) ([]float64, bool) {
+	switch c := channel.(type) {
+	case *Channel[float32]:
+		return toFloat64(c)
+	case *Channel[int16]:
+		return toFloat64(c)
+	case *Channel[int32]:
+		return toFloat64(c)
+	default:
+		return nil, false
+	}
+}
+
+func toFloat64[T float32 | int16 | int32](c *Channel[T]) ([]float64, bool) {
+	if c.Data == nil {
+		return nil, false
+	}
+
+	samples := make([]float64, len(*c.Data))
+	for i, v := range *c.Data {
+		samples[i] = float64(v)
+	}
+	return samples, true
+}
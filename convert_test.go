@@ -0,0 +1,41 @@
+package motecldparser
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestInt16ToFloat32(t *testing.T) {
+	c := &Channel[int16]{Frequency: 10, Name: "Temp", ShortName: "T", Unit: "C", Data: &[]int16{215, -10, 0}}
+
+	got := Int16ToFloat32(c, 0.1)
+	if !slices.Equal(*got.Data, []float32{21.5, -1, 0}) {
+		t.Errorf("got %v", *got.Data)
+	}
+	if got.Name != "Temp" || got.ShortName != "T" || got.Unit != "C" || got.Frequency != 10 {
+		t.Errorf("metadata not preserved: %+v", got)
+	}
+	if empty := Int16ToFloat32(&Channel[int16]{}, 1); len(*empty.Data) != 0 {
+		t.Errorf("nil data: got %v", *empty.Data)
+	}
+}
+
+func TestFloat32ToInt16(t *testing.T) {
+	data := []float32{21.5, -1.04, 5000, -5000, float32(math.NaN())}
+	c := &Channel[float32]{Frequency: 10, Name: "Temp", Data: &data}
+
+	got, clamped := Float32ToInt16(c, 0.1)
+	if want := []int16{215, -10, math.MaxInt16, math.MinInt16, 0}; !slices.Equal(*got.Data, want) {
+		t.Errorf("got %v, want %v", *got.Data, want)
+	}
+	if clamped != 3 {
+		t.Errorf("clamped = %d, want 3", clamped)
+	}
+	if got.Name != "Temp" || got.Frequency != 10 {
+		t.Errorf("metadata not preserved: %+v", got)
+	}
+	if data[0] != 21.5 {
+		t.Errorf("original modified: %v", data)
+	}
+}
@@ -0,0 +1,166 @@
+package motecldparser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CSVStream builds a File from CSV data one row at a time.
+//
+// The first row of the CSV holds the channel names. It may be followed by a
+// row of units, recognized by having at least one field that is not a number.
+// Every following row holds one sample per channel. Each column becomes a
+// float32 channel sampled at the frequency given to NewCSVStream.
+//
+// Rows are parsed and appended to the channels as Next is called, so the CSV
+// text is never held in memory: only the decoded samples are, at four bytes
+// per value. Once Next returns false and Err returns nil, File holds the
+// complete channels, ready to be written.
+//
+// Example:
+//
+//	stream, err := motecldparser.NewCSVStream(src, 100)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for stream.Next() {
+//	    // stream.Row() holds the values of the current row
+//	}
+//	if err := stream.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = stream.File().Write(fd)
+type CSVStream struct {
+	reader   *csv.Reader
+	file     *File
+	channels []*Channel[float32]
+	pending  []string // First data row, read while looking for units
+	row      []float64
+	line     int
+	err      error
+}
+
+// NewCSVStream reads the header and the optional units row of a CSV stream.
+//
+// Returns an error if the header cannot be read. Empty cells in data rows are
+// stored as NaN.
+func NewCSVStream(r io.Reader, freq uint16) (*CSVStream, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	s := &CSVStream{reader: reader, file: &File{}, line: 1}
+	for _, name := range header {
+		data := make([]float32, 0)
+		channel := &Channel[float32]{Frequency: freq, Name: strings.TrimSpace(name), Data: &data}
+		s.channels = append(s.channels, channel)
+		s.file.AddChannels(channel)
+	}
+	s.row = make([]float64, len(header))
+
+	// The second row is either the units or the first samples
+	record, err := reader.Read()
+	switch {
+	case errors.Is(err, io.EOF):
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("read CSV line 2: %w", err)
+	}
+	s.line++
+
+	if isUnitsRow(record) {
+		for i, unit := range record {
+			s.channels[i].Unit = strings.TrimSpace(unit)
+		}
+	} else {
+		s.pending = append([]string(nil), record...)
+	}
+
+	return s, nil
+}
+
+// Next parses the next row and appends its values to the channels.
+//
+// It returns false at the end of the data or on the first error, which is
+// then reported by Err.
+func (s *CSVStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	record := s.pending
+	s.pending = nil
+	if record == nil {
+		var err error
+		record, err = s.reader.Read()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.err = fmt.Errorf("read CSV line %d: %w", s.line+1, err)
+			}
+			return false
+		}
+		s.line++
+	}
+
+	for i, field := range record {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			s.row[i] = math.NaN()
+		} else {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				s.err = fmt.Errorf("CSV line %d, column %q: %w", s.line, s.channels[i].Name, err)
+				return false
+			}
+			s.row[i] = v
+		}
+	}
+
+	for i, v := range s.row {
+		s.channels[i].AddData(float32(v))
+	}
+
+	return true
+}
+
+// Row returns the values of the row parsed by the last call to Next.
+//
+// The slice is reused by the next call to Next.
+func (s *CSVStream) Row() []float64 {
+	return s.row
+}
+
+// Err returns the first error encountered by Next, if any.
+func (s *CSVStream) Err() error {
+	return s.err
+}
+
+// File returns the file holding the channels built so far.
+//
+// Session metadata is left empty for the caller to fill in.
+func (s *CSVStream) File() *File {
+	return s.file
+}
+
+// isUnitsRow reports whether a CSV record holds units rather than samples.
+func isUnitsRow(record []string) bool {
+	for _, field := range record {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package motecldparser
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		wantUnits []string
+		wantData  [][]float32
+		wantErr   string
+	}{
+		{
+			name:      "with units",
+			csv:       "Speed, RPM\nkm/h,rpm\n1,100\n2,200\n",
+			wantUnits: []string{"km/h", "rpm"},
+			wantData:  [][]float32{{1, 2}, {100, 200}},
+		},
+		{
+			name:      "without units",
+			csv:       "Speed,RPM\n1,100\n2,200\n",
+			wantUnits: []string{"", ""},
+			wantData:  [][]float32{{1, 2}, {100, 200}},
+		},
+		{
+			name:      "units row with an empty cell",
+			csv:       "Speed,RPM\n,rpm\n1,100\n",
+			wantUnits: []string{"", "rpm"},
+			wantData:  [][]float32{{1}, {100}},
+		},
+		{
+			name:      "header only",
+			csv:       "Speed,RPM\n",
+			wantUnits: []string{"", ""},
+			wantData:  [][]float32{{}, {}},
+		},
+		{
+			name:    "invalid number",
+			csv:     "Speed,RPM\n1,100\n2,fast\n",
+			wantErr: `line 3, column "RPM"`,
+		},
+		{
+			name:    "wrong number of fields",
+			csv:     "Speed,RPM\n1,100\n2\n",
+			wantErr: "line 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewCSVStream(strings.NewReader(tt.csv), 50)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for s.Next() {
+			}
+
+			if tt.wantErr != "" {
+				if s.Err() == nil || !strings.Contains(s.Err().Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", s.Err(), tt.wantErr)
+				}
+				return
+			}
+			if s.Err() != nil {
+				t.Fatal(s.Err())
+			}
+
+			f := s.File()
+			for i, channel := range f.Channels {
+				c := channel.(*Channel[float32])
+				if c.Unit != tt.wantUnits[i] || c.Frequency != 50 {
+					t.Errorf("channel %d: unit %q at %d Hz, want %q", i, c.Unit, c.Frequency, tt.wantUnits[i])
+				}
+				if !slices.Equal(*c.Data, tt.wantData[i]) {
+					t.Errorf("channel %d: got %v, want %v", i, *c.Data, tt.wantData[i])
+				}
+			}
+			if name := f.Channels[0].(*Channel[float32]).Name; name != "Speed" {
+				t.Errorf("name = %q", name)
+			}
+		})
+	}
+}
+
+func TestCSVStreamRow(t *testing.T) {
+	s, err := NewCSVStream(strings.NewReader("A,B\n1,\n"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Next() {
+		t.Fatal(s.Err())
+	}
+	if row := s.Row(); row[0] != 1 || !math.IsNaN(row[1]) {
+		t.Errorf("row = %v, want an empty cell as NaN", row)
+	}
+	if s.Next() {
+		t.Error("Next returned true at the end of the data")
+	}
+}
+
+func TestNewCSVStreamEmpty(t *testing.T) {
+	if _, err := NewCSVStream(strings.NewReader(""), 10); err == nil {
+		t.Error("got nil error")
+	}
+}
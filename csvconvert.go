@@ -0,0 +1,76 @@
+package motecldparser
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ConvertOptions configures ConvertCSV.
+type ConvertOptions struct {
+	Frequency uint16 // Sampling frequency of the CSV rows in Hz (required)
+
+	Time         time.Time // Timestamp of the session
+	Driver       string    // Name of the driver
+	Vehicle      string    // Vehicle identifier or name
+	Venue        string    // Track or venue name
+	ShortComment string    // Brief description or notes
+	EventName    string    // Name of the event
+	EventSession string    // Session identifier
+}
+
+// ConvertCSV converts the CSV file at csvPath to an LD file at ldPath.
+//
+// The CSV is parsed like CSVStream does: a header row of channel names, an
+// optional row of units and one row per sample, every column becoming a
+// float32 channel sampled at opts.Frequency. The session metadata is taken
+// from opts and the file is checked with Validate before anything is written,
+// so a CSV with empty cells, stored as NaN, is rejected. ldPath is only
+// created once the file is valid.
+//
+// Returns ErrInvalidFrequency if opts.Frequency is zero, the errors of
+// CSVStream and Validate, and any error creating or writing ldPath.
+//
+// Example:
+//
+//	err := motecldparser.ConvertCSV("session.csv", "session.ld", motecldparser.ConvertOptions{
+//	    Frequency: 100,
+//	    Time:      time.Now(),
+//	    Driver:    "Driver Name",
+//	})
+func ConvertCSV(csvPath, ldPath string, opts ConvertOptions) error {
+	if opts.Frequency == 0 {
+		return fmt.Errorf("%w: ConvertOptions.Frequency is zero", ErrInvalidFrequency)
+	}
+
+	src, err := os.Open(csvPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	stream, err := NewCSVStream(src, opts.Frequency)
+	if err != nil {
+		return err
+	}
+	for stream.Next() {
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+
+	f := stream.File()
+	f.Time = opts.Time
+	f.Driver = opts.Driver
+	f.Vehicle = opts.Vehicle
+	f.Venue = opts.Venue
+	f.ShortComment = opts.ShortComment
+	f.EventName = opts.EventName
+	f.EventSession = opts.EventSession
+
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	return writeFile(f, ldPath)
+}
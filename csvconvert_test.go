@@ -0,0 +1,67 @@
+package motecldparser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConvertCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		freq    uint16
+		wantErr error
+	}{
+		{name: "valid", csv: "Speed,RPM\nkm/h,rpm\n1,100\n2,200\n", freq: 10},
+		{name: "empty cell", csv: "Speed,RPM\n1,100\n2,\n", freq: 10, wantErr: ErrNonFiniteValue},
+		{name: "zero frequency", csv: "Speed\n1\n", freq: 0, wantErr: ErrInvalidFrequency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			csvPath := filepath.Join(dir, "in.csv")
+			ldPath := filepath.Join(dir, "out.ld")
+			if err := os.WriteFile(csvPath, []byte(tt.csv), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := ConvertOptions{Frequency: tt.freq, Time: time.Date(2024, 5, 17, 14, 30, 0, 0, time.UTC), Driver: "Driver"}
+			err := ConvertCSV(csvPath, ldPath, opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			fd, openErr := os.Open(ldPath)
+			if tt.wantErr != nil {
+				if openErr == nil {
+					fd.Close()
+					t.Error("output created for an invalid CSV")
+				}
+				return
+			}
+			if openErr != nil {
+				t.Fatal(openErr)
+			}
+			defer fd.Close()
+
+			read, err := Read(fd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read.Driver != "Driver" || !read.Time.Equal(opts.Time) || len(read.Channels) != 2 {
+				t.Errorf("got driver %q, time %v, %d channels", read.Driver, read.Time, len(read.Channels))
+			}
+		})
+	}
+}
+
+func TestConvertCSVMissingFile(t *testing.T) {
+	err := ConvertCSV(filepath.Join(t.TempDir(), "missing.csv"), filepath.Join(t.TempDir(), "out.ld"), ConvertOptions{Frequency: 10})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,80 @@
+package motecldparser
+
+import "fmt"
+
+// Derivative returns the rate of change per second of a float32 channel.
+//
+// Each sample is the difference from the previous sample multiplied by the
+// channel Frequency, so the result is expressed per second (e.g. speed in m/s
+// becomes acceleration in m/s/s). The first sample uses the forward
+// difference, i.e. it equals the second one. A channel with fewer than two
+// samples has a zero derivative.
+//
+// The result keeps Name, ShortName and Frequency, and "/s" is appended to
+// Unit. The original channel is not modified.
+func Derivative(c *Channel[float32]) *Channel[float32] {
+	n := c.SampleCount()
+	data := make([]float32, n)
+	for i := 1; i < n; i++ {
+		data[i] = ((*c.Data)[i] - (*c.Data)[i-1]) * float32(c.Frequency)
+	}
+
+	if n > 1 {
+		data[0] = data[1]
+	}
+
+	unit := "/s"
+	if c.Unit != "" {
+		unit = c.Unit + "/s"
+	}
+
+	return &Channel[float32]{
+		Frequency: c.Frequency,
+		Name:      c.Name,
+		ShortName: c.ShortName,
+		Unit:      unit,
+		Data:      &data,
+	}
+}
+
+// AddComputedChannel adds a float32 channel whose samples are computed by fn,
+// such as a math channel combining existing channels.
+//
+// fn is called with every sample index in order and is expected to read the
+// samples of the source channels at that index. Indexes only line up between
+// channels sampled at the same rate, so the sources must be sampled at freq:
+// the new channel is as long as the longest channel of the file sampled at
+// freq, and ErrInvalidFrequency is returned if there is none. Resample the
+// sources first (see Normalize) to combine channels of different rates.
+//
+// Example:
+//
+//	torque, rpm := *torqueChannel.Data, *rpmChannel.Data
+//	err := file.AddComputedChannel("Power", "kW", 100, func(i int) float32 {
+//	    return torque[i] * rpm[i] * 2 * math.Pi / 60 / 1000
+//	})
+func (f *File) AddComputedChannel(name, unit string, freq uint16, fn func(sampleIndex int) float32) error {
+	n := -1
+	for _, c := range f.AnyChannels() {
+		if freq != 0 && c.ChannelFrequency() == freq {
+			n = max(n, c.SampleCount())
+		}
+	}
+
+	if n < 0 {
+		return fmt.Errorf("%w: no channel sampled at %d Hz", ErrInvalidFrequency, freq)
+	}
+
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = fn(i)
+	}
+
+	f.AddChannels(&Channel[float32]{
+		Frequency: freq,
+		Name:      name,
+		Unit:      unit,
+		Data:      &data,
+	})
+	return nil
+}
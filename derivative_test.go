@@ -0,0 +1,58 @@
+package motecldparser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDerivative(t *testing.T) {
+	tests := []struct {
+		name     string
+		unit     string
+		data     []float32
+		want     []float32
+		wantUnit string
+	}{
+		{name: "linear", unit: "m", data: []float32{0, 1, 2, 3}, want: []float32{10, 10, 10, 10}, wantUnit: "m/s"},
+		{name: "varying", unit: "m/s", data: []float32{0, 1, 3}, want: []float32{10, 10, 20}, wantUnit: "m/s/s"},
+		{name: "single sample", data: []float32{5}, want: []float32{0}, wantUnit: "/s"},
+		{name: "empty", data: []float32{}, want: []float32{}, wantUnit: "/s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Channel[float32]{Frequency: 10, Name: "Distance", Unit: tt.unit, Data: &tt.data}
+			got := Derivative(c)
+			if !slices.Equal(*got.Data, tt.want) {
+				t.Errorf("got %v, want %v", *got.Data, tt.want)
+			}
+			if got.Unit != tt.wantUnit || got.Name != "Distance" || got.Frequency != 10 {
+				t.Errorf("channel = %+v", got)
+			}
+		})
+	}
+}
+
+func TestAddComputedChannel(t *testing.T) {
+	f := twoChannelFile()
+	speed := *f.Channels[0].(*Channel[float32]).Data
+
+	err := f.AddComputedChannel("Double", "km/h", 10, func(i int) float32 { return speed[i] * 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	double := f.Channels[2].(*Channel[float32])
+	if !slices.Equal(*double.Data, []float32{2, 4, 6, 8}) || double.Name != "Double" || double.Unit != "km/h" {
+		t.Errorf("got %+v %v", double, *double.Data)
+	}
+
+	for _, freq := range []uint16{0, 7} {
+		if err := f.AddComputedChannel("Other", "", freq, func(int) float32 { return 0 }); !errors.Is(err, ErrInvalidFrequency) {
+			t.Errorf("%d Hz: got %v, want ErrInvalidFrequency", freq, err)
+		}
+	}
+	if len(f.Channels) != 3 {
+		t.Errorf("got %d channels, want 3", len(f.Channels))
+	}
+}
@@ -0,0 +1,44 @@
+package motecldparser
+
+import "fmt"
+
+// FieldError describes a problem with a single field of the file or of one
+// of its channels.
+//
+// Validation functions return FieldError values, joined with errors.Join when
+// several problems are found, so callers can find out programmatically which
+// channel and field are affected (e.g. to highlight them in a UI):
+//
+//	var fieldErr *FieldError
+//	if errors.As(err, &fieldErr) {
+//	    fmt.Println(fieldErr.Channel, fieldErr.Field)
+//	}
+//
+// FieldError wraps one of the package's sentinel errors, so errors.Is can be
+// used to test for a specific kind of problem.
+type FieldError struct {
+	Channel int    // Index of the channel in File.Channels, or -1 for file fields
+	Field   string // Name of the offending field (e.g. "Name", "EventComment")
+	Reason  string // Details about the problem, may be empty
+	Err     error  // Sentinel error describing the kind of problem
+}
+
+// Error returns a description of the problem including its location.
+func (e *FieldError) Error() string {
+	msg := e.Field
+	if e.Channel >= 0 {
+		msg = fmt.Sprintf("channel %d %s", e.Channel, e.Field)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error describing the kind of problem.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
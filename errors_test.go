@@ -0,0 +1,26 @@
+package motecldparser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFieldError(t *testing.T) {
+	tests := []struct {
+		err  *FieldError
+		want string
+	}{
+		{&FieldError{Channel: -1, Field: "Driver", Reason: "65 bytes", Err: ErrStringTooLong}, "Driver: motecldparser: string too long: 65 bytes"},
+		{&FieldError{Channel: 2, Field: "Name", Err: ErrEmptyName}, "channel 2 Name: motecldparser: empty channel name"},
+		{&FieldError{Channel: 0, Field: "Data"}, "channel 0 Data"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, tt.err.Err) && tt.err.Err != nil {
+			t.Errorf("%q does not wrap %v", tt.err, tt.err.Err)
+		}
+	}
+}
@@ -1,8 +1,8 @@
-// Package motecldparser provides functionality for writing MoTeC LD (Logged Data) files.
+// Package motecldparser provides functionality for reading and writing MoTeC LD (Logged Data) files.
 //
 // MoTeC LD files are binary files used by MoTeC data acquisition systems to store
-// telemetry data from racing vehicles. This package supports creating and writing
-// LD files with multiple channels of different data types (float32, int16, int32).
+// telemetry data from racing vehicles. This package supports creating, writing and
+// reading LD files with multiple channels of different data types (float32, int16, int32).
 //
 // Basic usage:
 //
@@ -17,12 +17,18 @@
 //	    Data:      &[]float32{0, 10, 20},
 //	}
 //	file.AddChannels(channel)
-//	file.Write(fileDescriptor)
+//	if err := file.Write(fileDescriptor); err != nil {
+//	    log.Fatal(err)
+//	}
 package motecldparser
 
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"time"
 
@@ -60,6 +66,22 @@ import (
 //   - EventComment: max 1024 bytes
 //   - VehicleId: max 64 bytes
 //   - VehicleType, VehicleComment: max 32 bytes
+//
+// ShortComment and EventComment are independent fields stored in different
+// blocks. ShortComment lives in the file header next to the driver, vehicle
+// and venue, and suits a one-line summary of the session (e.g. "New front
+// wing"). EventComment lives in the event block with EventName and
+// EventSession and is meant for longer notes. Neither is derived from the
+// other; both are written and read back as-is.
+//
+// The format has no numeric session or outing number: EventSession is the
+// only session identifier. To make files sort correctly, include a
+// zero-padded outing number in it (e.g. "Practice 03").
+//
+// No logging window or trigger offset is stored either: every channel starts
+// at Time. Data captured before a trigger (a pre-trigger buffer) cannot be
+// marked as such; drop those samples and move Time to the trigger before
+// writing if the session should start there.
 type File struct {
 	Time         time.Time // Timestamp of when the data was logged
 	Driver       string    // Name of the driver
@@ -72,13 +94,34 @@ type File struct {
 	EventComment string // Detailed event description or notes
 
 	VehicleId      string // Unique vehicle identifier
-	VehicleWeight  uint32 // Vehicle weight in kilograms
+	VehicleWeight  uint32 // Vehicle weight in kilograms (see CheckVehicleWeight)
 	VehicleType    string // Vehicle type or class (e.g., "GT3", "Formula")
 	VehicleComment string // Additional vehicle notes
 
 	Channels []interface{} // Collection of Channel pointers (use AddChannels to add)
+	Laps     []Lap         // Laps of the session, not stored in the LD file (see DeriveLaps)
+
+	RequireChannels bool // Refuse to write a file without channels (see ErrNoChannels)
+
+	LastWritePlan  *WritePlan // Layout used by the last successful write, for debugging
+	Concurrency    int        // Channels written at once by WriteConcurrent (0 means GOMAXPROCS)
+	AutoName       bool       // Name unnamed channels "Channel N" when writing
+	DeltaTransform bool       // Delta-encode integer channels in WriteGzip (see WriteGzip)
+
+	// OnWarn, if set, is called by Write with a description of every problem
+	// written as-is: truncated strings, non-finite samples and the other
+	// problems reported by Validate, CheckUnits and CheckVehicleWeight.
+	OnWarn func(msg string)
 }
 
+// ErrNoChannels is returned when writing a file without channels while
+// File.RequireChannels is set.
+var ErrNoChannels = errors.New("motecldparser: file has no channels")
+
+// ErrNotSeekable is returned when writing to a destination that does not
+// support seeking, such as a pipe.
+var ErrNotSeekable = errors.New("motecldparser: destination is not seekable; use WriteTo with a buffer")
+
 // Channel represents a single data channel in a MoTeC LD file.
 //
 // A channel contains a series of measurements sampled at a specific frequency.
@@ -89,9 +132,24 @@ type File struct {
 //
 // String field limits when written to the binary file:
 //   - Name: max 32 bytes
-//   - ShortName: max 8 bytes
+//   - ShortName: max 8 bytes (longer short names are rejected by File.Write)
 //   - Unit: max 12 bytes
 //
+// The channel metadata has no room for a free-text comment; see
+// ldfile.LdFileChannelMeta. Display properties such as the trace color or
+// style are not stored either, in the LD file or in the .ldx file: i2 keeps
+// them in its workspaces, so they cannot be set from this package. The same
+// goes for a display range: no minimum or maximum field is known in the
+// channel metadata, and the .ldx file only holds markers (see
+// WriteSimpleLdx), so i2 scales graphs from the data itself.
+//
+// Shift, Mul, Scale and DecPlaces describe how the stored samples map to
+// physical values (see ScaledValues). They are mostly useful for integer
+// channels; the zero values leave the samples unscaled. Shift offsets values,
+// not time: the format has no per-channel start offset and every channel
+// starts at File.Time. A channel that started logging later must be padded at
+// the start of Data to line up, e.g. with NaN samples for float channels.
+//
 // Example:
 //
 //	speedChannel := &Channel[float32]{
@@ -107,6 +165,39 @@ type Channel[T float32 | int16 | int32] struct {
 	ShortName string // Abbreviated name (displayed in compact views)
 	Unit      string // Unit of measurement (e.g., "km/h", "rpm", "°C")
 	Data      *[]T   // Pointer to the data array
+
+	Shift     int16 // Offset added to the scaled samples
+	Mul       int16 // Multiplier applied after the offset (0 means 1)
+	Scale     int16 // Divisor applied to the stored samples (0 means 1)
+	DecPlaces int16 // Power of ten the stored samples are divided by
+
+	Id uint16 // Channel ID read from the file; ignored by Write
+}
+
+// AnyChannel is the set of methods shared by every Channel instantiation.
+//
+// It is implemented by *Channel[float32], *Channel[int16], *Channel[int32] and
+// the StreamChannel equivalents, and lets callers work with channels of any
+// supported type without type switches. The unexported methods prevent
+// implementations outside this package.
+//
+// Example:
+//
+//	for _, c := range file.AnyChannels() {
+//	    fmt.Println(c.ChannelName(), c.ChannelFrequency(), c.SampleCount())
+//	}
+type AnyChannel interface {
+	ChannelName() string      // Full channel name
+	ChannelShortName() string // Abbreviated name
+	ChannelUnit() string      // Unit of measurement
+	ChannelFrequency() uint16 // Sampling frequency in Hz
+	SampleCount() int         // Number of samples in the channel
+
+	setName(name string)
+	setUnit(unit string)
+	spec() (ChannelSpec, bool)
+	validate(n int) []error
+	write(w io.WriteSeeker, n uint16, channelsCount uint32, channelsMetaPointer uintptr, currentDataPointer uintptr) (uintptr, error)
 }
 
 // Write writes the complete MoTeC LD file to the provided file descriptor.
@@ -121,6 +212,31 @@ type Channel[T float32 | int16 | int32] struct {
 //   - Writing event, venue, and vehicle information blocks
 //   - Writing channel metadata and data for all channels
 //
+// Channel IDs (0x2EE1 + index) and the links between channel metadata blocks
+// are derived from the order of File.Channels every time the file is written.
+// Channels can therefore be added, removed, reordered or merged from other
+// files freely: the written file is always internally consistent and no
+// reindexing step is needed. The Id field of read channels is not reused.
+//
+// Pointers in the format are uint32, so every channel's data must start within
+// the first 4 GiB of the file. Files that cannot be addressed, because of too
+// many channels or too much data before a channel, make Write fail with
+// ErrFileTooLarge naming the channel where the limit is crossed. The metadata
+// region is checked before anything is written.
+//
+// Strings longer than their field are truncated, except channel short names:
+// a ShortName longer than 8 bytes makes Write fail with a *FieldError wrapping
+// ErrStringTooLong before anything is written.
+//
+// If AutoName is set, channels with an empty Name are renamed "Channel N",
+// where N is their 1-based position in File.Channels, before anything is
+// written. The new names are kept in the channels.
+//
+// A file without channels is valid: it holds only the session metadata, and
+// its channel data pointer equals its channel metadata pointer. Set
+// RequireChannels to get ErrNoChannels instead, e.g. to catch a misconfigured
+// pipeline before it ships empty files.
+//
 // Example:
 //
 //	fd, err := os.Create("telemetry.ld")
@@ -128,23 +244,124 @@ type Channel[T float32 | int16 | int32] struct {
 //	    log.Fatal(err)
 //	}
 //	defer fd.Close()
-//	file.Write(fd)
-//
-// Note: This method does not return an error. Any write errors will cause a panic.
-// Consider wrapping file operations in appropriate error handling.
-func (f *File) Write(fd *os.File) {
-	// Calculate pointers
-	headerSize := uintptr(binary.Size(ldfile.LdFileHead{}))
-	eventSize := uintptr(binary.Size(ldfile.LdFileEvent{}))
-	venueSize := uintptr(binary.Size(ldfile.LdFileVenue{}))
-	vehicleSize := uintptr(binary.Size(ldfile.LdFileVehicle{}))
-	channelMetaSize := uintptr(binary.Size(ldfile.LdFileChannelMeta{}))
-
-	eventPointer := headerSize
-	venuePointer := eventPointer + eventSize
-	vehiclePointer := venuePointer + venueSize
-	channelsMetaPointer := vehiclePointer + vehicleSize
-	channelsDataPointer := channelsMetaPointer + channelMetaSize*uintptr(len(f.Channels))
+//	if err := file.Write(fd); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Write returns the first error encountered while writing. The file contents
+// are undefined if an error is returned. Writing requires seeking, so Write
+// fails with ErrNotSeekable before writing anything if fd is a pipe or another
+// non-seekable file; use WriteTo for those.
+func (f *File) Write(fd *os.File) error {
+	return f.write(fd)
+}
+
+// write serializes the file to any seekable destination.
+func (f *File) write(fd io.WriteSeeker) error {
+	if _, err := fd.Seek(0, io.SeekCurrent); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotSeekable, err)
+	}
+
+	if err := f.beforeWrite(); err != nil {
+		return err
+	}
+
+	// Calculate pointers and write the session metadata
+	plan := f.planSections()
+	if err := plan.checkAddressing(); err != nil {
+		return err
+	}
+
+	// When every channel size is known, verify the complete layout before
+	// anything is written; the layout actually written is checked again below
+	if _, err := f.Plan(); err != nil && !errors.Is(err, ErrUnknownSize) {
+		return err
+	}
+
+	if err := f.writeSections(fd, plan); err != nil {
+		return err
+	}
+
+	// Write channels
+	channelsCount := uint32(len(f.Channels))
+	channelsMetaPointer := uintptr(plan.ChannelsMetaPointer)
+	currentDataPointer := uintptr(plan.ChannelsDataPointer)
+	for i, channel := range f.Channels {
+		if err := checkDataPointer(i, int64(currentDataPointer)); err != nil {
+			return err
+		}
+		plan.Channels[i].DataPointer = int64(currentDataPointer)
+
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		nextDataPointer, err := c.write(fd, uint16(i), channelsCount, channelsMetaPointer, currentDataPointer)
+		if err != nil {
+			return fmt.Errorf("write channel %d: %w", i, err)
+		}
+
+		plan.Channels[i].DataLength = c.SampleCount()
+		plan.Channels[i].DataSize = int64(nextDataPointer - currentDataPointer)
+		currentDataPointer = nextDataPointer
+	}
+
+	plan.Size = int64(currentDataPointer)
+	if err := plan.check(); err != nil {
+		return err
+	}
+	f.LastWritePlan = plan
+
+	return nil
+}
+
+// beforeWrite runs the checks shared by all write paths and reports the
+// problems written as-is to OnWarn.
+func (f *File) beforeWrite() error {
+	if f.RequireChannels && len(f.AnyChannels()) == 0 {
+		return fmt.Errorf("%w: add at least one channel before writing", ErrNoChannels)
+	}
+
+	// Short names are too tight to be cut silently: "Oil Temp" and "Oil Temp2"
+	// would both become "Oil Temp"
+	var errs []error
+	for i, c := range f.Channels {
+		if c, ok := c.(AnyChannel); ok && len(c.ChannelShortName()) > 8 {
+			errs = append(errs, &FieldError{Channel: i, Field: "ShortName", Reason: fmt.Sprintf("%d bytes, max 8", len(c.ChannelShortName())), Err: ErrStringTooLong})
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if f.AutoName {
+		for i, channel := range f.Channels {
+			if c, ok := channel.(AnyChannel); ok && c.ChannelName() == "" {
+				c.setName(fmt.Sprintf("Channel %d", i+1))
+			}
+		}
+	}
+
+	if f.OnWarn != nil {
+		warnings := append(f.problems(), f.CheckUnits()...)
+		if err := f.CheckVehicleWeight(); err != nil {
+			warnings = append(warnings, err)
+		}
+		for _, err := range warnings {
+			f.OnWarn(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// writeSections writes the header and the event, venue and vehicle blocks at
+// the offsets given by the plan.
+func (f *File) writeSections(fd io.WriteSeeker, plan *WritePlan) error {
+	eventPointer := uintptr(plan.EventPointer)
+	venuePointer := uintptr(plan.VenuePointer)
+	vehiclePointer := uintptr(plan.VehiclePointer)
 
 	// Create the file header
 	head := ldfile.LdFileHead{
@@ -160,8 +377,8 @@ func (f *File) Write(fd *os.File) {
 		ChannelsCount:    uint32(len(f.Channels)),
 
 		EventPointer:        uint32(eventPointer),
-		ChannelsMetaPointer: uint32(channelsMetaPointer),
-		ChannelsDataPointer: uint32(channelsDataPointer),
+		ChannelsMetaPointer: uint32(plan.ChannelsMetaPointer),
+		ChannelsDataPointer: uint32(plan.ChannelsDataPointer),
 	}
 
 	date := f.Time.Format("02/01/2006")
@@ -200,32 +417,43 @@ func (f *File) Write(fd *os.File) {
 	copy(vehicle.Comment[:], f.VehicleComment)
 
 	// Write to file
-	binary.Write(fd, binary.LittleEndian, head)
-
-	fd.Seek(int64(eventPointer), 0)
-	binary.Write(fd, binary.LittleEndian, event)
+	if err := writeAt(fd, 0, head); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	if err := writeAt(fd, eventPointer, event); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	if err := writeAt(fd, venuePointer, venue); err != nil {
+		return fmt.Errorf("write venue: %w", err)
+	}
+	if err := writeAt(fd, vehiclePointer, vehicle); err != nil {
+		return fmt.Errorf("write vehicle: %w", err)
+	}
 
-	fd.Seek(int64(venuePointer), 0)
-	binary.Write(fd, binary.LittleEndian, venue)
+	return nil
+}
 
-	fd.Seek(int64(vehiclePointer), 0)
-	binary.Write(fd, binary.LittleEndian, vehicle)
+// writeAt seeks to the given offset and writes data in little-endian order.
+func writeAt(w io.WriteSeeker, offset uintptr, data any) error {
+	if _, err := w.Seek(int64(offset), io.SeekStart); err != nil {
+		return fmt.Errorf("seek to %d: %w", offset, err)
+	}
+	return binary.Write(w, binary.LittleEndian, data)
+}
 
-	// Write channels
-	currentDataPointer := channelsDataPointer
-	for i, channel := range f.Channels {
-		switch any(channel).(type) {
-		case *Channel[float32]:
-			currentDataPointer = channel.(*Channel[float32]).Write(fd, uint16(i), head.ChannelsCount, channelsMetaPointer, currentDataPointer)
-			break
-		case *Channel[int16]:
-			currentDataPointer = channel.(*Channel[int16]).Write(fd, uint16(i), head.ChannelsCount, channelsMetaPointer, currentDataPointer)
-			break
-		case *Channel[int32]:
-			currentDataPointer = channel.(*Channel[int32]).Write(fd, uint16(i), head.ChannelsCount, channelsMetaPointer, currentDataPointer)
-			break
+// AnyChannels returns the file's channels as AnyChannel values.
+//
+// This allows querying the name, frequency and length of every channel
+// without asserting the concrete Channel type. Entries of File.Channels
+// that are not channels are skipped.
+func (f *File) AnyChannels() []AnyChannel {
+	channels := make([]AnyChannel, 0, len(f.Channels))
+	for _, channel := range f.Channels {
+		if c, ok := channel.(AnyChannel); ok {
+			channels = append(channels, c)
 		}
 	}
+	return channels
 }
 
 // AddChannels adds one or more channels to the file.
@@ -233,13 +461,40 @@ func (f *File) Write(fd *os.File) {
 // Channels must be pointers to Channel instances with appropriate type parameters.
 // Multiple channels can be added in a single call.
 //
+// Channels are appended in order, so existing indexes in File.Channels never
+// change. Returns the index of the first added channel; the others follow it.
+//
 // Example:
 //
 //	speedChannel := &Channel[float32]{...}
 //	rpmChannel := &Channel[int16]{...}
-//	file.AddChannels(speedChannel, rpmChannel)
-func (f *File) AddChannels(channels ...interface{}) {
+//	first := file.AddChannels(speedChannel, rpmChannel) // rpm is at first+1
+func (f *File) AddChannels(channels ...interface{}) int {
+	first := len(f.Channels)
 	f.Channels = append(f.Channels, channels...)
+	return first
+}
+
+// ChannelIndex returns the index in File.Channels of the first channel with
+// the given name, and false if there is none.
+func (f *File) ChannelIndex(name string) (int, bool) {
+	for i, channel := range f.Channels {
+		if c, ok := channel.(AnyChannel); ok && c.ChannelName() == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// SetComments sets ShortComment and EventComment, truncating them to fit.
+//
+// The short comment is limited to 64 bytes and the event comment to 1024
+// bytes. Longer values are cut at a character boundary, so multi-byte UTF-8
+// characters are never split. Returns true if either comment was truncated.
+func (f *File) SetComments(short, event string) bool {
+	f.ShortComment = truncateString(short, 64)
+	f.EventComment = truncateString(event, 1024)
+	return len(f.ShortComment) < len(short) || len(f.EventComment) < len(event)
 }
 
 // Write writes a single channel's metadata and data to the file.
@@ -249,82 +504,127 @@ func (f *File) AddChannels(channels ...interface{}) {
 // binary data to the appropriate locations in the file.
 //
 // Parameters:
-//   - fd: the file descriptor to write to
+//   - w: the destination to write to
 //   - n: the channel index (0-based)
 //   - channelsCount: total number of channels in the file
 //   - channelsMetaPointer: file offset where channel metadata begins
 //   - currentDataPointer: file offset where this channel's data should be written
 //
-// Returns the file offset for the next channel's data.
+// Returns the file offset for the next channel's data, or the first error
+// encountered while writing.
 //
 // This method should not typically be called directly by users.
 func (c *Channel[T]) Write(
-	fd *os.File,
+	w io.WriteSeeker,
 	n uint16,
 	channelsCount uint32,
 	channelsMetaPointer uintptr,
 	currentDataPointer uintptr,
-) uintptr {
-	var dataType ldfile.DataType
-	var previousMetaPointer uintptr = 0
-	var nextMetaPointer uintptr = 0
+) (uintptr, error) {
+	currentMetaPointer, channelMeta := newChannelMeta(dataTypeOf[T](), n, channelsCount, channelsMetaPointer, currentDataPointer)
+	channelMeta.DataLength = uint32(c.SampleCount())
+	channelMeta.Frequency = c.Frequency
+	channelMeta.Shift = c.Shift
+	channelMeta.DecPlaces = c.DecPlaces
+	if c.Mul != 0 {
+		channelMeta.Mul = c.Mul
+	}
+	if c.Scale != 0 {
+		channelMeta.Scale = c.Scale
+	}
+
+	copy(channelMeta.Name[:], c.Name)
+	copy(channelMeta.ShortName[:], c.ShortName)
+	copy(channelMeta.Unit[:], c.Unit)
+
+	// Convert data to binary slice
+	binaryData, err := c.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to file
+	if err := writeAt(w, currentMetaPointer, channelMeta); err != nil {
+		return 0, fmt.Errorf("write metadata: %w", err)
+	}
+	if err := writeAt(w, currentDataPointer, binaryData); err != nil {
+		return 0, fmt.Errorf("write data: %w", err)
+	}
+
+	// Return next data pointer
+	nextDataPointer := currentDataPointer + uintptr(len(binaryData))
+	return nextDataPointer, nil
+}
 
-	switch any(c).(type) {
-	case *Channel[float32]:
-		dataType = ldfile.DataTypeFloat32
-		break
-	case *Channel[int16]:
-		dataType = ldfile.DataTypeInt16
-		break
-	case *Channel[int32]:
-		dataType = ldfile.DataTypeInt32
-		break
+// MarshalBinary returns the channel samples encoded in little-endian order.
+//
+// The result contains only the sample data, without any metadata, and is
+// exactly what Write stores in the channel's data region. It is mostly useful
+// for debugging and for comparing a channel against other tools.
+//
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (c *Channel[T]) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if c.Data != nil {
+		if err := binary.Write(buf, binary.LittleEndian, *c.Data); err != nil {
+			return nil, err
+		}
 	}
+	return buf.Bytes(), nil
+}
+
+// dataTypeOf returns the LD data type used to store samples of type T.
+func dataTypeOf[T float32 | int16 | int32]() ldfile.DataType {
+	var zero T
+	switch any(zero).(type) {
+	case int16:
+		return ldfile.DataTypeInt16
+	case int32:
+		return ldfile.DataTypeInt32
+	default:
+		return ldfile.DataTypeFloat32
+	}
+}
+
+// newChannelMeta builds the metadata of the n-th channel, linking it to the
+// adjacent channels, and returns it together with its file offset.
+//
+// The caller is responsible for filling in the channel's descriptive fields,
+// frequency and data length.
+func newChannelMeta(
+	dataType ldfile.DataType,
+	n uint16,
+	channelsCount uint32,
+	channelsMetaPointer uintptr,
+	currentDataPointer uintptr,
+) (uintptr, ldfile.LdFileChannelMeta) {
+	var previousMetaPointer uintptr = 0
+	var nextMetaPointer uintptr = 0
 
 	if n > 0 {
-		previousMetaPointer = channelsMetaPointer + uintptr(binary.Size(ldfile.LdFileChannelMeta{}))*(uintptr(n-1))
+		previousMetaPointer = channelsMetaPointer + uintptr(channelMetaSize)*(uintptr(n-1))
 	}
 
-	if n < uint16(channelsCount-1) {
-		nextMetaPointer = channelsMetaPointer + uintptr(binary.Size(ldfile.LdFileChannelMeta{}))*(uintptr(n+1))
+	if uint32(n)+1 < channelsCount {
+		nextMetaPointer = channelsMetaPointer + uintptr(channelMetaSize)*(uintptr(n+1))
 	}
 
-	currentMetaPointer := channelsMetaPointer + uintptr(binary.Size(ldfile.LdFileChannelMeta{}))*uintptr(n)
+	currentMetaPointer := channelsMetaPointer + uintptr(channelMetaSize)*uintptr(n)
 
 	channelMeta := ldfile.LdFileChannelMeta{
 		PreviousMetaPointer: uint32(previousMetaPointer),
 		NextMetaPointer:     uint32(nextMetaPointer),
 		DataPointer:         uint32(currentDataPointer),
-		DataLength:          uint32(len(*c.Data)),
 		ChannelId:           0x2EE1 + n,
 		DataType:            dataType.DataType,
 		DataTypeLength:      dataType.DataTypeLength,
-		Frequency:           c.Frequency,
 		Shift:               0,
 		Mul:                 1,
 		Scale:               1,
 		DecPlaces:           0,
 	}
 
-	copy(channelMeta.Name[:], c.Name)
-	copy(channelMeta.ShortName[:], c.ShortName)
-	copy(channelMeta.Unit[:], c.Unit)
-
-	// Convert data to binary slice
-	binaryDataWriter := new(bytes.Buffer)
-	binary.Write(binaryDataWriter, binary.LittleEndian, c.Data)
-	binaryData := binaryDataWriter.Bytes()
-
-	// Write to file
-	fd.Seek(int64(currentMetaPointer), 0)
-	binary.Write(fd, binary.LittleEndian, channelMeta)
-
-	fd.Seek(int64(currentDataPointer), 0)
-	binary.Write(fd, binary.LittleEndian, binaryData)
-
-	// Return next data pointer
-	nextDataPointer := currentDataPointer + uintptr(len(binaryData))
-	return nextDataPointer
+	return currentMetaPointer, channelMeta
 }
 
 // AddData appends a single data point to the channel.
@@ -344,3 +644,81 @@ func (c *Channel[T]) Write(
 func (c *Channel[T]) AddData(data T) {
 	*c.Data = append(*c.Data, data)
 }
+
+// ChannelName returns the full name of the channel.
+func (c *Channel[T]) ChannelName() string {
+	return c.Name
+}
+
+// ChannelShortName returns the abbreviated name of the channel.
+func (c *Channel[T]) ChannelShortName() string {
+	return c.ShortName
+}
+
+// ChannelUnit returns the unit of measurement of the channel.
+func (c *Channel[T]) ChannelUnit() string {
+	return c.Unit
+}
+
+// ChannelFrequency returns the sampling frequency of the channel in Hz.
+func (c *Channel[T]) ChannelFrequency() uint16 {
+	return c.Frequency
+}
+
+// SampleCount returns the number of samples in the channel.
+//
+// A channel with nil Data has zero samples.
+func (c *Channel[T]) SampleCount() int {
+	if c.Data == nil {
+		return 0
+	}
+	return len(*c.Data)
+}
+
+func (c *Channel[T]) write(w io.WriteSeeker, n uint16, channelsCount uint32, channelsMetaPointer uintptr, currentDataPointer uintptr) (uintptr, error) {
+	return c.Write(w, n, channelsCount, channelsMetaPointer, currentDataPointer)
+}
+
+// Timestamps returns the time of every sample, given the session start time.
+//
+// Sample i is timestamped start + i/Frequency seconds. Each timestamp is
+// computed from its index rather than accumulated, so no rounding error builds
+// up over long channels, and the result is allocated in a single slice.
+// Returns nil if the channel has no samples or a zero Frequency.
+//
+// Example:
+//
+//	for i, t := range channel.Timestamps(file.Time) {
+//	    fmt.Println(t.Format(time.RFC3339Nano), (*channel.Data)[i])
+//	}
+func (c *Channel[T]) Timestamps(start time.Time) []time.Time {
+	n := c.SampleCount()
+	if n == 0 || c.Frequency == 0 {
+		return nil
+	}
+
+	timestamps := make([]time.Time, n)
+	for i := range timestamps {
+		timestamps[i] = start.Add(time.Duration(i) * time.Second / time.Duration(c.Frequency))
+	}
+	return timestamps
+}
+
+// IsMonotonic reports whether the samples of the channel never decrease.
+//
+// It is meant for cumulative channels such as distance or time, which i2 uses
+// as the x axis of its plots. If a sample is lower than the previous one, or
+// is NaN, IsMonotonic returns false and the index of the first such sample;
+// otherwise it returns true and -1.
+func (c *Channel[T]) IsMonotonic() (bool, int) {
+	if c.Data == nil {
+		return true, -1
+	}
+
+	for i, v := range *c.Data {
+		if math.IsNaN(float64(v)) || (i > 0 && v < (*c.Data)[i-1]) {
+			return false, i
+		}
+	}
+	return true, -1
+}
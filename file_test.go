@@ -0,0 +1,294 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// benchFile returns a file with the given number of float32 channels of the
+// given number of samples each.
+func benchFile(channels, samples int) *File {
+	f := &File{}
+	for i := 0; i < channels; i++ {
+		data := make([]float32, samples)
+		for j := range data {
+			data[j] = float32(i*samples + j)
+		}
+		f.AddChannels(&Channel[float32]{Frequency: 100, Name: fmt.Sprintf("Channel %d", i), Data: &data})
+	}
+	return f
+}
+
+func TestAnyChannels(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", ShortName: "SPD", Unit: "km/h", Data: &[]float32{1, 2, 3}},
+		"not a channel",
+		&Channel[int16]{Frequency: 5, Name: "Gear", Data: &[]int16{1}},
+		&Channel[int32]{Frequency: 1, Name: "Lap"},
+	)
+
+	type summary struct {
+		name, shortName, unit string
+		frequency             uint16
+		samples               int
+	}
+	var got []summary
+	for _, c := range f.AnyChannels() {
+		got = append(got, summary{c.ChannelName(), c.ChannelShortName(), c.ChannelUnit(), c.ChannelFrequency(), c.SampleCount()})
+	}
+
+	want := []summary{
+		{"Speed", "SPD", "km/h", 10, 3},
+		{"Gear", "", "", 5, 1},
+		{"Lap", "", "", 1, 0},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	f := twoChannelFile()
+	f.Time = time.Date(2024, 5, 17, 14, 30, 5, 0, time.UTC)
+	f.Vehicle = "Car"
+	f.EventName = "Event"
+	f.EventSession = "Race"
+	f.ShortComment = "Short"
+	f.VehicleId = "42"
+	f.VehicleWeight = 1250
+
+	path := filepath.Join(t.TempDir(), "file.ld")
+	if err := writeFile(f, path); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, writeBytes(t, f)) {
+		t.Error("Write and WriteTo output differ")
+	}
+
+	read, err := Read(bytes.NewReader(written))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Time.Equal(f.Time) {
+		t.Errorf("time = %v, want %v", read.Time, f.Time)
+	}
+	got := []string{read.Driver, read.Vehicle, read.Venue, read.EventName, read.EventSession, read.ShortComment, read.VehicleId}
+	want := []string{"Driver", "Car", "Venue", "Event", "Race", "Short", "42"}
+	if !slices.Equal(got, want) {
+		t.Errorf("metadata = %q, want %q", got, want)
+	}
+	if read.VehicleWeight != 1250 {
+		t.Errorf("weight = %d", read.VehicleWeight)
+	}
+}
+
+func TestWriteRequireChannels(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := (&File{}).WriteTo(&buf); err != nil {
+		t.Fatalf("no channels: %v", err)
+	}
+
+	buf.Reset()
+	if _, err := (&File{RequireChannels: true}).WriteTo(&buf); !errors.Is(err, ErrNoChannels) {
+		t.Fatalf("no channels required: got %v, want ErrNoChannels", err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("wrote %d bytes before failing", buf.Len())
+	}
+}
+
+func TestWriteShortName(t *testing.T) {
+	tests := []struct {
+		shortName string
+		wantErr   error
+	}{
+		{shortName: "12345678"},
+		{shortName: "123456789", wantErr: ErrStringTooLong},
+	}
+
+	for _, tt := range tests {
+		f := &File{}
+		f.AddChannels(&Channel[int16]{Frequency: 1, Name: "Gear", ShortName: tt.shortName})
+
+		var buf bytes.Buffer
+		_, err := f.WriteTo(&buf)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%q: got %v, want %v", tt.shortName, err, tt.wantErr)
+		}
+		if err != nil && buf.Len() > 0 {
+			t.Errorf("%q: wrote %d bytes before failing", tt.shortName, buf.Len())
+		}
+	}
+}
+
+func TestWriteAutoName(t *testing.T) {
+	f := &File{AutoName: true}
+	f.AddChannels(
+		&Channel[int16]{Frequency: 1, Name: "Gear"},
+		&Channel[int16]{Frequency: 1},
+	)
+
+	read := roundTrip(t, f)
+	if name := f.Channels[1].(*Channel[int16]).Name; name != "Channel 2" {
+		t.Errorf("name = %q, want Channel 2", name)
+	}
+	if name := read.Channels[1].(*Channel[int16]).Name; name != "Channel 2" {
+		t.Errorf("name read back = %q, want Channel 2", name)
+	}
+}
+
+func TestWriteOnWarn(t *testing.T) {
+	var warnings []string
+	f := &File{OnWarn: func(msg string) { warnings = append(warnings, msg) }}
+	f.AddChannels(&Channel[float32]{Frequency: 10, Name: "Speed", Unit: "parsecs", Data: &[]float32{float32(math.NaN())}})
+
+	writeBytes(t, f)
+	// The unset vehicle weight is not reported
+	if len(warnings) != 2 {
+		t.Errorf("got warnings %q, want only the NaN sample and the unknown unit", warnings)
+	}
+}
+
+func TestChannelIndex(t *testing.T) {
+	f := twoChannelFile()
+	if i, ok := f.ChannelIndex("Gear"); !ok || i != 1 {
+		t.Errorf("Gear at %d, %v", i, ok)
+	}
+	if i, ok := f.ChannelIndex("Missing"); ok || i != -1 {
+		t.Errorf("Missing at %d, %v", i, ok)
+	}
+}
+
+func TestSetComments(t *testing.T) {
+	f := &File{}
+	if f.SetComments("short", "event") {
+		t.Error("short comments reported as truncated")
+	}
+
+	long := string(bytes.Repeat([]byte("é"), 40)) // 80 bytes
+	if !f.SetComments(long, "event") {
+		t.Error("long comment not reported as truncated")
+	}
+	if len(f.ShortComment) != 64 || f.ShortComment != long[:64] {
+		t.Errorf("short comment = %q", f.ShortComment)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	c := &Channel[int16]{Data: &[]int16{1, -2}}
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 0, 0xFE, 0xFF}; !bytes.Equal(data, want) {
+		t.Errorf("got % X, want % X", data, want)
+	}
+
+	if data, err := (&Channel[float32]{}).MarshalBinary(); err != nil || len(data) != 0 {
+		t.Errorf("nil data: got % X, %v", data, err)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &Channel[float32]{Frequency: 3, Data: &[]float32{0, 0, 0, 0}}
+
+	got := c.Timestamps(start)
+	want := []time.Time{start, start.Add(333333333), start.Add(666666666), start.Add(time.Second)}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := (&Channel[float32]{Data: &[]float32{1}}).Timestamps(start); got != nil {
+		t.Errorf("zero frequency: got %v", got)
+	}
+}
+
+func TestIsMonotonic(t *testing.T) {
+	tests := []struct {
+		data      []float32
+		want      bool
+		wantIndex int
+	}{
+		{data: nil, want: true, wantIndex: -1},
+		{data: []float32{1, 1, 2}, want: true, wantIndex: -1},
+		{data: []float32{1, 3, 2}, want: false, wantIndex: 2},
+		{data: []float32{float32(math.NaN()), 1}, want: false, wantIndex: 0},
+	}
+
+	for _, tt := range tests {
+		c := &Channel[float32]{Data: &tt.data}
+		if ok, i := c.IsMonotonic(); ok != tt.want || i != tt.wantIndex {
+			t.Errorf("%v: got %v, %d, want %v, %d", tt.data, ok, i, tt.want, tt.wantIndex)
+		}
+	}
+}
+
+func TestWriteMmap(t *testing.T) {
+	stream := twoChannelFile()
+	stream.AddChannels(&StreamChannel[int16]{Frequency: 1, Name: "Stream", Reader: bytes.NewReader([]byte{1, 0, 2, 0})})
+
+	for name, f := range map[string]*File{"mapped": benchFile(3, 1000), "stream fallback": stream} {
+		path := filepath.Join(t.TempDir(), "mmap.ld")
+		if err := f.WriteMmap(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		written, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := Read(bytes.NewReader(written))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(read.Channels) != len(f.Channels) {
+			t.Errorf("%s: read %d channels, want %d", name, len(read.Channels), len(f.Channels))
+		}
+		if name == "mapped" && !bytes.Equal(written, writeBytes(t, f)) {
+			t.Errorf("%s: WriteMmap and WriteTo output differ", name)
+		}
+	}
+}
+
+// BenchmarkWriteFile compares File.Write with the memory-mapped
+// File.WriteMmap on a 40 MiB file.
+func BenchmarkWriteFile(b *testing.B) {
+	f := benchFile(100, 100_000)
+	size, err := f.Size()
+	if err != nil {
+		b.Fatal(err)
+	}
+	path := filepath.Join(b.TempDir(), "bench.ld")
+
+	writers := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "Write", write: func() error { return writeFile(f, path) }},
+		{name: "WriteMmap", write: func() error { return f.WriteMmap(path) }},
+	}
+
+	for _, w := range writers {
+		b.Run(w.name, func(b *testing.B) {
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				if err := w.write(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
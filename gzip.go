@@ -0,0 +1,168 @@
+package motecldparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+// deltaComment marks the gzip header of files written with DeltaTransform.
+const deltaComment = "motecldparser: delta-encoded integer channels"
+
+// WriteGzip writes the file gzip-compressed to w, producing an .ld.gz file.
+//
+// The LD format has no compressed channel representation: every sample is
+// stored raw. Compressing the whole file is the only way to reduce its size,
+// and works well for sparse or slowly-changing channels. MoTeC i2 cannot open
+// compressed files, so they must be decompressed (e.g. with gunzip) before
+// use, or read back with ReadGzip.
+//
+// If File.DeltaTransform is set, the samples of int16 and int32 channels are
+// replaced by the difference from the previous sample before compressing.
+// Slowly-changing signals then become runs of small values that compress much
+// better. The transform is recorded in the gzip header and reversed by
+// ReadGzip, but the decompressed file is not a valid LD file: such files can
+// only be read back with ReadGzip, not opened in i2 after gunzip. It has no
+// effect outside of WriteGzip.
+//
+// The file is built in memory before being compressed, as with WriteTo.
+func (f *File) WriteGzip(w io.Writer) error {
+	buf := &writeBuffer{}
+	if err := f.write(buf); err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	if f.DeltaTransform {
+		if err := deltaTransform(buf.data, false); err != nil {
+			return err
+		}
+		zw.Comment = deltaComment
+	}
+
+	if _, err := zw.Write(buf.data); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadGzip parses a gzip-compressed MoTeC LD file such as one produced by
+// WriteGzip.
+//
+// The decompressed file is buffered in memory before being parsed with Read.
+// Files written with File.DeltaTransform are recognized from their gzip header
+// and decoded.
+func ReadGzip(r io.Reader) (*File, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	if zr.Comment == deltaComment {
+		if err := deltaTransform(data, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return Read(bytes.NewReader(data))
+}
+
+// deltaTransform delta-encodes, or decodes, the samples of the integer
+// channels of a serialized file in place.
+func deltaTransform(data []byte, decode bool) error {
+	_, l, err := readLayout(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for i, meta := range l.metas {
+		end := uint64(meta.DataPointer) + uint64(meta.DataLength)*uint64(meta.DataTypeLength)
+		if end > uint64(len(data)) {
+			return fmt.Errorf("%w: channel %d data ends at %d, file size %d", ErrInvalidPointer, i, end, len(data))
+		}
+		region := data[meta.DataPointer:end]
+
+		switch (ldfile.DataType{DataType: meta.DataType, DataTypeLength: meta.DataTypeLength}) {
+		case ldfile.DataTypeInt16:
+			deltaSamples(region, 2, decode)
+		case ldfile.DataTypeInt32:
+			deltaSamples(region, 4, decode)
+		}
+	}
+
+	return nil
+}
+
+// deltaSamples delta-encodes or decodes little-endian samples of the given
+// size. Differences wrap around, so the transform is exactly reversible.
+func deltaSamples(region []byte, size int, decode bool) {
+	get := func(i int) uint32 {
+		if size == 2 {
+			return uint32(binary.LittleEndian.Uint16(region[i*2:]))
+		}
+		return binary.LittleEndian.Uint32(region[i*4:])
+	}
+	set := func(i int, v uint32) {
+		if size == 2 {
+			binary.LittleEndian.PutUint16(region[i*2:], uint16(v))
+		} else {
+			binary.LittleEndian.PutUint32(region[i*4:], v)
+		}
+	}
+
+	n := len(region) / size
+	if decode {
+		for i := 1; i < n; i++ {
+			set(i, get(i)+get(i-1))
+		}
+	} else {
+		for i := n - 1; i > 0; i-- {
+			set(i, get(i)-get(i-1))
+		}
+	}
+}
+
+// compressionSampleSize is the number of bytes of channel data compressed by
+// CompressionRatioEstimate.
+const compressionSampleSize = 64 << 10
+
+// CompressionRatioEstimate estimates how well the channel data compresses.
+//
+// The first 64 KiB of the encoded samples are compressed with gzip and the
+// ratio of their size to the compressed size is returned: a constant channel
+// gives a large ratio and random noise a ratio close to 1 or below. The
+// estimate helps decide whether a file is worth writing with WriteGzip, or
+// whether int16 storage would help. A channel without samples returns 1.
+func (c *Channel[T]) CompressionRatioEstimate() float64 {
+	n := min(c.SampleCount(), compressionSampleSize/int(dataTypeOf[T]().DataTypeLength))
+	if n == 0 {
+		return 1
+	}
+
+	sample := *c
+	data := (*c.Data)[:n]
+	sample.Data = &data
+
+	raw, err := sample.MarshalBinary()
+	if err != nil {
+		return 1
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(raw)
+	zw.Close()
+
+	return float64(len(raw)) / float64(compressed.Len())
+}
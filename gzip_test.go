@@ -0,0 +1,101 @@
+package motecldparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"testing"
+)
+
+// gzipRoundTrip writes f with WriteGzip and returns the decompressed bytes
+// along with the file read back by ReadGzip.
+func gzipRoundTrip(t *testing.T, f *File) ([]byte, *File) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := f.WriteGzip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	compressed := buf.Bytes()
+
+	read, err := ReadGzip(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw, read
+}
+
+func TestWriteGzip(t *testing.T) {
+	raw, read := gzipRoundTrip(t, threeChannelFile())
+
+	checkThreeChannels(t, read)
+	if !bytes.Equal(raw, writeBytes(t, threeChannelFile())) {
+		t.Error("decompressed file differs from the LD file")
+	}
+}
+
+func TestWriteGzipDeltaTransform(t *testing.T) {
+	f := threeChannelFile()
+	f.DeltaTransform = true
+	raw, read := gzipRoundTrip(t, f)
+
+	checkThreeChannels(t, read)
+	if bytes.Equal(raw, writeBytes(t, threeChannelFile())) {
+		t.Error("decompressed file equal to the LD file despite the transform")
+	}
+}
+
+func TestDeltaSamples(t *testing.T) {
+	samples := []int16{100, 101, 99, -32768, 32767}
+	region := make([]byte, len(samples)*2)
+	for i, v := range samples {
+		region[i*2] = byte(v)
+		region[i*2+1] = byte(uint16(v) >> 8)
+	}
+	original := slices.Clone(region)
+
+	deltaSamples(region, 2, false)
+	if region[2] != 1 || region[3] != 0 {
+		t.Errorf("second sample encoded as % X, want 01 00", region[2:4])
+	}
+	deltaSamples(region, 2, true)
+	if !bytes.Equal(region, original) {
+		t.Errorf("round trip: got % X, want % X", region, original)
+	}
+}
+
+func TestReadGzipInvalid(t *testing.T) {
+	if _, err := ReadGzip(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("got nil error")
+	}
+}
+
+func TestCompressionRatioEstimate(t *testing.T) {
+	constant := make([]float32, 10000)
+	noise := make([]int32, 10000)
+	state := uint32(1)
+	for i := range noise {
+		state = state*1664525 + 1013904223
+		noise[i] = int32(state)
+	}
+
+	if ratio := (&Channel[float32]{Data: &constant}).CompressionRatioEstimate(); ratio < 10 {
+		t.Errorf("constant channel: ratio %v", ratio)
+	}
+	if ratio := (&Channel[int32]{Data: &noise}).CompressionRatioEstimate(); ratio > 1.1 {
+		t.Errorf("noise: ratio %v", ratio)
+	}
+	if ratio := (&Channel[int16]{}).CompressionRatioEstimate(); ratio != 1 {
+		t.Errorf("empty channel: ratio %v, want 1", ratio)
+	}
+}
@@ -0,0 +1,148 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// WriteTo writes the complete MoTeC LD file to w.
+//
+// The LD format is written out of order, so the file is first built in
+// memory and then copied to w in a single pass. This allows writing to
+// destinations that cannot seek, such as network connections or archive
+// writers, at the cost of holding the whole file in memory.
+//
+// WriteTo implements the io.WriterTo interface.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	buf := &writeBuffer{}
+	if err := f.write(buf); err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(buf.data)
+	return int64(n), err
+}
+
+// WriteAt writes the complete MoTeC LD file to w at offset 0.
+//
+// Every block is written with a single WriteAt call at its offset, so no
+// seekable destination or in-memory copy of the file is needed. This suits
+// object-store SDKs exposing io.WriterAt for multipart uploads. Unlike
+// WriteConcurrent, channels are written one at a time, so stream channels are
+// supported.
+//
+// Example:
+//
+//	if err := file.WriteAt(uploader); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *File) WriteAt(w io.WriterAt) error {
+	return f.write(io.NewOffsetWriter(w, 0))
+}
+
+// ReadFrom replaces the file contents with the MoTeC LD file read from r.
+//
+// Parsing requires random access, so r is read to the end and buffered in
+// memory before being parsed with Read. The returned count is the number of
+// bytes read from r. If an error is returned the file is left unchanged.
+//
+// Only the contents stored in an LD file are replaced: the session, event
+// and vehicle metadata and Channels. Options such as OnWarn, RequireChannels,
+// AutoName, Concurrency and DeltaTransform keep their values. Laps describe
+// the previous contents, so they are cleared.
+//
+// ReadFrom implements the io.ReaderFrom interface.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+
+	parsed, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return int64(len(data)), err
+	}
+
+	f.Time = parsed.Time
+	f.Driver = parsed.Driver
+	f.Vehicle = parsed.Vehicle
+	f.Venue = parsed.Venue
+	f.ShortComment = parsed.ShortComment
+	f.EventName = parsed.EventName
+	f.EventSession = parsed.EventSession
+	f.EventComment = parsed.EventComment
+	f.VehicleId = parsed.VehicleId
+	f.VehicleWeight = parsed.VehicleWeight
+	f.VehicleType = parsed.VehicleType
+	f.VehicleComment = parsed.VehicleComment
+	f.Channels = parsed.Channels
+	f.Laps = nil
+	return int64(len(data)), nil
+}
+
+// writeBuffer is an in-memory io.WriteSeeker.
+//
+// Seeking past the end and writing fills the gap with zeros, like a file. A
+// fixed buffer never reallocates its data and fails writes beyond its
+// capacity instead, so it can wrap memory it does not own.
+type writeBuffer struct {
+	data  []byte
+	pos   int64
+	fixed bool
+}
+
+func (b *writeBuffer) Write(p []byte) (int, error) {
+	end := b.pos + int64(len(p))
+	if end > int64(len(b.data)) {
+		if end > int64(cap(b.data)) {
+			if b.fixed {
+				return 0, io.ErrShortWrite
+			}
+
+			grown := make([]byte, len(b.data), max(end, 2*int64(cap(b.data))))
+			copy(grown, b.data)
+			b.data = grown
+		}
+		b.data = b.data[:end]
+	}
+
+	copy(b.data[b.pos:], p)
+	b.pos = end
+	return len(p), nil
+}
+
+func (b *writeBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.pos
+	case io.SeekEnd:
+		offset += int64(len(b.data))
+	default:
+		return 0, errors.New("motecldparser: invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("motecldparser: negative position")
+	}
+
+	b.pos = offset
+	return offset, nil
+}
+
+// writeFile creates the file at path and writes f to it with File.Write.
+func writeFile(f *File, path string) error {
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Write(fd); err != nil {
+		fd.Close()
+		return err
+	}
+
+	return fd.Close()
+}
@@ -0,0 +1,94 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := twoChannelFile().WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+	}
+
+	var warnings []string
+	f := &File{
+		Driver:          "Previous",
+		Laps:            []Lap{{Number: 1}},
+		RequireChannels: true,
+		AutoName:        true,
+		Concurrency:     3,
+		DeltaTransform:  true,
+		OnWarn:          func(msg string) { warnings = append(warnings, msg) },
+	}
+
+	read, err := f.ReadFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != n {
+		t.Errorf("ReadFrom returned %d, want %d", read, n)
+	}
+
+	if f.Driver != "Driver" || len(f.Channels) != 2 {
+		t.Errorf("contents not replaced: driver %q, %d channels", f.Driver, len(f.Channels))
+	}
+	if !f.RequireChannels || !f.AutoName || f.Concurrency != 3 || !f.DeltaTransform || f.OnWarn == nil {
+		t.Errorf("options reset: %+v", f)
+	}
+	if f.Laps != nil {
+		t.Errorf("laps of the previous contents kept: %v", f.Laps)
+	}
+}
+
+func TestReadFromInvalidKeepsFile(t *testing.T) {
+	f := twoChannelFile()
+	if _, err := f.ReadFrom(bytes.NewReader([]byte("not an LD file"))); err == nil {
+		t.Fatal("got nil error")
+	}
+	if f.Driver != "Driver" || len(f.Channels) != 2 {
+		t.Errorf("file modified on error")
+	}
+}
+
+func TestWriteAt(t *testing.T) {
+	f := twoChannelFile()
+	want := writeBytes(t, f)
+
+	var dst memWriterAt
+	if err := f.WriteAt(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dst, want) {
+		t.Errorf("WriteAt wrote %d bytes differing from the %d of WriteTo", len(dst), len(want))
+	}
+}
+
+// memWriterAt is an in-memory io.WriterAt growing as needed.
+type memWriterAt []byte
+
+func (m *memWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(*m) {
+		*m = append(*m, make([]byte, end-len(*m))...)
+	}
+	return copy((*m)[off:], p), nil
+}
+
+func TestWriteNotSeekable(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := twoChannelFile().Write(w); !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("got %v, want ErrNotSeekable", err)
+	}
+}
@@ -0,0 +1,187 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrChannelNotFound is returned when no channel has the requested name.
+	ErrChannelNotFound = errors.New("motecldparser: channel not found")
+
+	// ErrNoLaps is returned when an operation needs laps and File.Laps is empty.
+	ErrNoLaps = errors.New("motecldparser: file has no laps")
+)
+
+// LapDebounce is the minimum time between two lap triggers.
+//
+// DeriveLaps ignores rising edges closer than LapDebounce to the previous
+// accepted one, so a noisy beacon signal does not produce spurious laps.
+var LapDebounce = time.Second
+
+// Lap describes a lap of the session.
+//
+// Sample indexes refer to the channel the lap was derived from. Start and
+// Duration are independent of the channel frequency and can be used to locate
+// the lap in channels sampled at other rates.
+type Lap struct {
+	Number      int           // Lap number, starting at 1 for the out lap
+	StartSample int           // Index of the first sample of the lap
+	EndSample   int           // Index one past the last sample of the lap
+	Start       time.Duration // Time from the start of the session to the start of the lap
+	Duration    time.Duration // Lap time
+}
+
+// DeriveLaps computes the laps of the session from a lap trigger channel.
+//
+// The trigger channel is expected to be zero except when the beacon is
+// crossed. Every rising edge (a non-zero sample following a zero sample)
+// starts a new lap; edges closer than LapDebounce to the previous one are
+// ignored. The laps cover the whole channel: the first lap runs from the start
+// of the session to the first trigger (the out lap) and the last one from the
+// last trigger to the end of the data (the in lap).
+//
+// Returns ErrChannelNotFound if no channel is named triggerChannel.
+func (f *File) DeriveLaps(triggerChannel string) ([]Lap, error) {
+	var channel AnyChannel
+	for _, c := range f.AnyChannels() {
+		if c.ChannelName() == triggerChannel {
+			channel = c
+			break
+		}
+	}
+
+	if channel == nil {
+		return nil, fmt.Errorf("%w: %q", ErrChannelNotFound, triggerChannel)
+	}
+
+	samples, ok := float64Samples(channel)
+	if !ok {
+		return nil, fmt.Errorf("channel %q: %w", triggerChannel, ErrNilData)
+	}
+
+	frequency := channel.ChannelFrequency()
+	if frequency == 0 {
+		return nil, fmt.Errorf("channel %q: %w", triggerChannel, ErrInvalidFrequency)
+	}
+
+	sampleTime := func(i int) time.Duration {
+		return time.Duration(i) * time.Second / time.Duration(frequency)
+	}
+
+	// Find the lap boundaries
+	boundaries := []int{0}
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] != 0 || samples[i] == 0 {
+			continue
+		}
+
+		last := boundaries[len(boundaries)-1]
+		if last > 0 && sampleTime(i)-sampleTime(last) < LapDebounce {
+			continue
+		}
+
+		boundaries = append(boundaries, i)
+	}
+	boundaries = append(boundaries, len(samples))
+
+	laps := make([]Lap, 0, len(boundaries)-1)
+	for i := 0; i < len(boundaries)-1; i++ {
+		start, end := boundaries[i], boundaries[i+1]
+		if start == end {
+			continue
+		}
+
+		laps = append(laps, Lap{
+			Number:      len(laps) + 1,
+			StartSample: start,
+			EndSample:   end,
+			Start:       sampleTime(start),
+			Duration:    sampleTime(end) - sampleTime(start),
+		})
+	}
+
+	return laps, nil
+}
+
+// SplitByLaps returns one file per lap of File.Laps.
+//
+// Each file holds the samples of every channel between the start and the end
+// of its lap, located by time so that channels at any frequency are sliced
+// consistently. Lap boundaries are rounded to the nearest sample, so the
+// channel the laps were derived from is split exactly at their sample indexes.
+// Session metadata is copied, with Time moved to the start of the lap and the
+// lap number appended to EventSession (e.g. "Race Lap 3"). The data is copied,
+// so the files are independent of f and of each other.
+//
+// Set File.Laps first, for example from DeriveLaps. Returns ErrNoLaps if
+// File.Laps is empty and ErrUnknownSize if the file contains a StreamChannel,
+// whose samples are not held in memory.
+//
+// Example:
+//
+//	file.Laps, err = file.DeriveLaps("Lap Trigger")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	laps, err := file.SplitByLaps()
+func (f *File) SplitByLaps() ([]*File, error) {
+	if len(f.Laps) == 0 {
+		return nil, ErrNoLaps
+	}
+
+	files := make([]*File, len(f.Laps))
+	for i, lap := range f.Laps {
+		lapFile := *f
+		lapFile.Channels = make([]interface{}, 0, len(f.Channels))
+		lapFile.Laps = nil
+		lapFile.LastWritePlan = nil
+		lapFile.Time = f.Time.Add(lap.Start)
+		lapFile.EventSession = strings.TrimSpace(fmt.Sprintf("%s Lap %d", f.EventSession, lap.Number))
+
+		for j, channel := range f.Channels {
+			var sliced any
+			switch c := channel.(type) {
+			case *Channel[float32]:
+				sliced = sliceChannel(c, lap)
+			case *Channel[int16]:
+				sliced = sliceChannel(c, lap)
+			case *Channel[int32]:
+				sliced = sliceChannel(c, lap)
+			case AnyChannel:
+				return nil, fmt.Errorf("channel %d (%q): %w", j, c.ChannelName(), ErrUnknownSize)
+			default:
+				continue
+			}
+			lapFile.Channels = append(lapFile.Channels, sliced)
+		}
+
+		files[i] = &lapFile
+	}
+
+	return files, nil
+}
+
+// sliceChannel returns a copy of the channel holding only the samples of the
+// given lap.
+func sliceChannel[T float32 | int16 | int32](c *Channel[T], lap Lap) *Channel[T] {
+	sliced := *c
+
+	// Round to the nearest sample: lap times were truncated to the
+	// nanosecond, so truncating again would land one sample early whenever
+	// the frequency does not divide one second
+	n := c.SampleCount()
+	index := func(d time.Duration) int {
+		return min(n, int((d*time.Duration(c.Frequency)+time.Second/2)/time.Second))
+	}
+	start, end := index(lap.Start), index(lap.Start+lap.Duration)
+
+	data := make([]T, end-start)
+	if len(data) > 0 {
+		copy(data, (*c.Data)[start:end])
+	}
+	sliced.Data = &data
+	return &sliced
+}
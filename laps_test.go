@@ -0,0 +1,173 @@
+package motecldparser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeriveLaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		freq    uint16
+		trigger []int16
+		want    []Lap
+	}{
+		{
+			name:    "no trigger",
+			freq:    2,
+			trigger: []int16{0, 0, 0, 0},
+			want:    []Lap{{Number: 1, StartSample: 0, EndSample: 4, Duration: 2 * time.Second}},
+		},
+		{
+			name:    "two triggers",
+			freq:    2,
+			trigger: []int16{0, 0, 1, 0, 0, 0, 1, 1, 0, 0},
+			want: []Lap{
+				{Number: 1, StartSample: 0, EndSample: 2, Duration: time.Second},
+				{Number: 2, StartSample: 2, EndSample: 6, Start: time.Second, Duration: 2 * time.Second},
+				{Number: 3, StartSample: 6, EndSample: 10, Start: 3 * time.Second, Duration: 2 * time.Second},
+			},
+		},
+		{
+			name:    "debounced",
+			freq:    4,
+			trigger: []int16{0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0},
+			want: []Lap{
+				{Number: 1, StartSample: 0, EndSample: 4, Duration: time.Second},
+				{Number: 2, StartSample: 4, EndSample: 12, Start: time.Second, Duration: 2 * time.Second},
+			},
+		},
+		{
+			name:    "trigger at the start",
+			freq:    2,
+			trigger: []int16{1, 0, 0},
+			want:    []Lap{{Number: 1, StartSample: 0, EndSample: 3, Duration: 1500 * time.Millisecond}},
+		},
+		{
+			name:    "empty",
+			freq:    2,
+			trigger: []int16{},
+			want:    []Lap{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{}
+			f.AddChannels(&Channel[int16]{Frequency: tt.freq, Name: "Beacon", Data: &tt.trigger})
+
+			got, err := f.DeriveLaps("Beacon")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveLapsErrors(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[int16]{Frequency: 0, Name: "Zero", Data: &[]int16{0}},
+		&Channel[int16]{Frequency: 10, Name: "Nil"},
+	)
+
+	tests := []struct {
+		channel string
+		want    error
+	}{
+		{channel: "Missing", want: ErrChannelNotFound},
+		{channel: "Zero", want: ErrInvalidFrequency},
+		{channel: "Nil", want: ErrNilData},
+	}
+	for _, tt := range tests {
+		if _, err := f.DeriveLaps(tt.channel); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.channel, err, tt.want)
+		}
+	}
+}
+
+func TestSplitByLaps(t *testing.T) {
+	start := time.Date(2024, 5, 17, 14, 0, 0, 0, time.UTC)
+	f := &File{Time: start, EventSession: "Race"}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 4, Name: "Speed", Data: &[]float32{1, 2, 3, 4, 5, 6, 7, 8}},
+		&Channel[int16]{Frequency: 2, Name: "Gear", Data: &[]int16{1, 2, 3, 4}},
+	)
+	f.Laps = []Lap{
+		{Number: 1, Duration: 500 * time.Millisecond},
+		{Number: 2, Start: 500 * time.Millisecond, Duration: 1500 * time.Millisecond},
+	}
+
+	files, err := f.SplitByLaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+
+	lap2 := files[1]
+	if lap2.EventSession != "Race Lap 2" || !lap2.Time.Equal(start.Add(500*time.Millisecond)) || lap2.Laps != nil {
+		t.Errorf("lap 2 metadata: %q %v %v", lap2.EventSession, lap2.Time, lap2.Laps)
+	}
+	if speed := *lap2.Channels[0].(*Channel[float32]).Data; !slices.Equal(speed, []float32{3, 4, 5, 6, 7, 8}) {
+		t.Errorf("lap 2 speed = %v", speed)
+	}
+	if gear := *lap2.Channels[1].(*Channel[int16]).Data; !slices.Equal(gear, []int16{2, 3, 4}) {
+		t.Errorf("lap 2 gear = %v", gear)
+	}
+
+	// The data is copied
+	(*files[0].Channels[0].(*Channel[float32]).Data)[0] = 100
+	if (*f.Channels[0].(*Channel[float32]).Data)[0] != 1 {
+		t.Error("lap data shares the original slice")
+	}
+}
+
+// TestSplitByLapsMatchesDeriveLaps splits channels at frequencies that do not
+// divide one second, where lap times are truncated to the nanosecond.
+func TestSplitByLapsMatchesDeriveLaps(t *testing.T) {
+	for _, freq := range []uint16{3, 7, 300} {
+		trigger := make([]int16, 4*int(freq))
+		trigger[int(freq)*7/3] = 1 // mid-second, on a truncated sample time
+		f := &File{}
+		f.AddChannels(&Channel[int16]{Frequency: freq, Name: "Beacon", Data: &trigger})
+
+		laps, err := f.DeriveLaps("Beacon")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Laps = laps
+
+		files, err := f.SplitByLaps()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, lap := range laps {
+			data := *files[i].Channels[0].(*Channel[int16]).Data
+			if len(data) != lap.EndSample-lap.StartSample {
+				t.Errorf("%d Hz lap %d: got %d samples, want %d", freq, lap.Number, len(data), lap.EndSample-lap.StartSample)
+			}
+			if i > 0 && len(data) > 0 && data[0] != 1 {
+				t.Errorf("%d Hz lap %d does not start on the trigger", freq, lap.Number)
+			}
+		}
+	}
+}
+
+func TestSplitByLapsErrors(t *testing.T) {
+	if _, err := twoChannelFile().SplitByLaps(); !errors.Is(err, ErrNoLaps) {
+		t.Errorf("no laps: got %v, want ErrNoLaps", err)
+	}
+
+	f := &File{Laps: []Lap{{Number: 1, Duration: time.Second}}}
+	f.AddChannels(&StreamChannel[float32]{Frequency: 10, Name: "Stream"})
+	if _, err := f.SplitByLaps(); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("stream: got %v, want ErrUnknownSize", err)
+	}
+}
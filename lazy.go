@@ -0,0 +1,254 @@
+package motecldparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+// lazyChunkSize is the size of the buffer LazyFile reads raw samples into.
+const lazyChunkSize = 64 << 10
+
+// LazyFile gives access to a MoTeC LD file without decoding its channel data
+// up front.
+//
+// Opening a LazyFile reads only the header and the channel metadata. Channel
+// data is read from the underlying io.ReaderAt when requested, so memory use
+// does not depend on the size of the file. The reader must stay open for as
+// long as the LazyFile is used.
+//
+// A LazyFile is not safe for concurrent use, as it reuses an internal buffer
+// between reads.
+type LazyFile struct {
+	// Metadata holds the session, event, venue and vehicle information.
+	// Its Channels field is always empty.
+	Metadata File
+
+	r       io.ReaderAt
+	metas   []ldfile.LdFileChannelMeta
+	scratch []byte
+}
+
+// OpenLazy reads the header and channel metadata of a MoTeC LD file.
+//
+// The same structural checks as Read are performed, but no channel data is
+// decoded.
+func OpenLazy(r io.ReaderAt) (*LazyFile, error) {
+	f, l, err := readLayout(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LazyFile{Metadata: *f, r: r, metas: l.metas}, nil
+}
+
+// Channels returns a summary of every channel of the file.
+func (lf *LazyFile) Channels() []ChannelInfo {
+	infos := make([]ChannelInfo, len(lf.metas))
+	for i, meta := range lf.metas {
+		infos[i] = ChannelInfo{
+			Name:      cString(meta.Name[:]),
+			ShortName: cString(meta.ShortName[:]),
+			Unit:      cString(meta.Unit[:]),
+			Frequency: meta.Frequency,
+			Samples:   int(meta.DataLength),
+		}
+		if meta.Frequency > 0 {
+			infos[i].Duration = float64(meta.DataLength) / float64(meta.Frequency)
+		}
+	}
+	return infos
+}
+
+// ChannelInto decodes the channel with the given name into buf.
+//
+// Samples are converted to float64 and scaled to physical values using the
+// channel's Shift, Mul, Scale and DecPlaces fields:
+//
+//	value = (raw / Scale * 10^-DecPlaces + Shift) * Mul
+//
+// where a zero Mul or Scale is treated as 1.
+//
+// Buffer reuse contract: ChannelInto never allocates a new result slice. If
+// buf holds at least as many elements as the channel has samples, the samples
+// are stored at the start of buf and their count is returned. Otherwise buf is
+// left untouched and io.ErrShortBuffer is returned together with the number of
+// samples of the channel, so the caller can grow buf and retry. Reusing the
+// same buf across calls keeps GC pressure low when scanning many channels or
+// files. Raw samples are read in chunks through a small internal buffer, so
+// buf is the only memory that grows with the size of the channel.
+//
+// Returns ErrChannelNotFound if no channel has the given name.
+func (lf *LazyFile) ChannelInto(name string, buf []float64) (int, error) {
+	meta, ok := lf.meta(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrChannelNotFound, name)
+	}
+
+	n := int(meta.DataLength)
+	if len(buf) < n {
+		return n, io.ErrShortBuffer
+	}
+
+	// Decode through a fixed-size scratch buffer, so buf is the only
+	// allocation that grows with the channel
+	if lf.scratch == nil {
+		lf.scratch = make([]byte, lazyChunkSize)
+	}
+	width := int(meta.DataTypeLength)
+	chunk := lazyChunkSize / max(width, 1)
+	for start := 0; start == 0 || start < n; start += chunk {
+		end := min(n, start+chunk)
+		raw := lf.scratch[:(end-start)*width]
+		if _, err := lf.r.ReadAt(raw, int64(meta.DataPointer)+int64(start*width)); err != nil {
+			return 0, fmt.Errorf("read channel %q data: %w", name, err)
+		}
+
+		if err := decodeSamples(buf[start:end], raw, meta); err != nil {
+			return 0, fmt.Errorf("channel %q: %w", name, err)
+		}
+	}
+
+	return n, nil
+}
+
+// meta returns the metadata of the first channel with the given name.
+func (lf *LazyFile) meta(name string) (ldfile.LdFileChannelMeta, bool) {
+	for _, meta := range lf.metas {
+		// Compare the raw bytes, so the lookup does not allocate a string
+		// for every channel
+		raw := meta.Name[:]
+		if i := bytes.IndexByte(raw, 0); i >= 0 {
+			raw = raw[:i]
+		}
+		if string(raw) == name {
+			return meta, true
+		}
+	}
+	return ldfile.LdFileChannelMeta{}, false
+}
+
+// decodeSamples decodes little-endian raw samples into dst and scales them to
+// physical values.
+func decodeSamples(dst []float64, raw []byte, meta ldfile.LdFileChannelMeta) error {
+	dataType := ldfile.DataType{DataType: meta.DataType, DataTypeLength: meta.DataTypeLength}
+	switch dataType {
+	case ldfile.DataTypeFloat32:
+		for i := range dst {
+			dst[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:])))
+		}
+	case ldfile.DataTypeInt16:
+		for i := range dst {
+			dst[i] = float64(int16(binary.LittleEndian.Uint16(raw[i*2:])))
+		}
+	case ldfile.DataTypeInt32:
+		for i := range dst {
+			dst[i] = float64(int32(binary.LittleEndian.Uint32(raw[i*4:])))
+		}
+	case ldfile.DataTypeFloat16:
+		for i := range dst {
+			dst[i] = float64(float16ToFloat32(binary.LittleEndian.Uint16(raw[i*2:])))
+		}
+	default:
+		return fmt.Errorf("%w: 0x%X with %d-byte samples", ErrUnsupportedDataType, meta.DataType, meta.DataTypeLength)
+	}
+
+	shift, factor := scaling(meta.Shift, meta.Mul, meta.Scale, meta.DecPlaces)
+	if shift != 0 || factor != 1 {
+		for i := range dst {
+			dst[i] = dst[i]*factor + shift
+		}
+	}
+
+	return nil
+}
+
+// ReadChannelsAsFloat64 reads every channel of a MoTeC LD file as float64
+// samples, keyed by channel name.
+//
+// It is the simplest way to inspect a file without dealing with channel
+// types. Samples of every data type are converted to float64 and scaled to
+// physical values like LazyFile.ChannelInto:
+//
+//	value = (raw / Scale * 10^-DecPlaces + Shift) * Mul
+//
+// where a zero Mul or Scale is treated as 1. The second map holds the
+// frequency of each channel in Hz. If several channels share a name, only the
+// first one is returned. Use Read to get the channels with their original
+// type and raw samples.
+//
+// Example:
+//
+//	samples, frequencies, err := motecldparser.ReadChannelsAsFloat64(fd)
+//	fmt.Println(len(samples["Speed"]), frequencies["Speed"])
+func ReadChannelsAsFloat64(r io.ReaderAt) (map[string][]float64, map[string]uint16, error) {
+	lf, err := OpenLazy(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	samples := make(map[string][]float64, len(lf.metas))
+	frequencies := make(map[string]uint16, len(lf.metas))
+	for _, meta := range lf.metas {
+		name := cString(meta.Name[:])
+		if _, ok := samples[name]; ok {
+			continue
+		}
+
+		values := make([]float64, meta.DataLength)
+		if _, err := lf.ChannelInto(name, values); err != nil {
+			return nil, nil, err
+		}
+
+		samples[name] = values
+		frequencies[name] = meta.Frequency
+	}
+
+	return samples, frequencies, nil
+}
+
+// ReadChannelsAsFloat32 reads every channel of a MoTeC LD file as float32
+// samples, keyed by channel name.
+//
+// It is like ReadChannelsAsFloat64, scaling samples of every data type to
+// physical values, but keeps half the memory, which suits viewers. float32
+// holds integers exactly only up to 2^24, so large int32 samples (e.g.
+// counters or timestamps) and finely scaled values lose precision; use
+// ReadChannelsAsFloat64 or Read for those. If several channels share a name,
+// only the first one is returned.
+func ReadChannelsAsFloat32(r io.ReaderAt) (map[string][]float32, error) {
+	lf, err := OpenLazy(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode through a single float64 buffer, grown to the longest channel
+	var buf []float64
+	samples := make(map[string][]float32, len(lf.metas))
+	for _, meta := range lf.metas {
+		name := cString(meta.Name[:])
+		if _, ok := samples[name]; ok {
+			continue
+		}
+
+		if len(buf) < int(meta.DataLength) {
+			buf = make([]float64, meta.DataLength)
+		}
+		n, err := lf.ChannelInto(name, buf)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]float32, n)
+		for i, v := range buf[:n] {
+			values[i] = float32(v)
+		}
+		samples[name] = values
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,265 @@
+package motecldparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// sineReader generates n little-endian float32 samples of a sine wave, so
+// large files can be written without holding their data in memory.
+type sineReader struct {
+	n, i int
+}
+
+func (s *sineReader) Read(p []byte) (int, error) {
+	written := 0
+	for ; s.i < s.n && len(p)-written >= 4; s.i++ {
+		value := float32(math.Sin(float64(s.i) / 100))
+		binary.LittleEndian.PutUint32(p[written:], math.Float32bits(value))
+		written += 4
+	}
+	if written == 0 && s.i == s.n {
+		return 0, io.EOF
+	}
+	return written, nil
+}
+
+// writeSyntheticFile writes an LD file with a generated "Speed" channel of
+// the given number of float32 samples and a small "Gear" channel, and returns
+// its path.
+func writeSyntheticFile(t testing.TB, samples int) string {
+	t.Helper()
+
+	f := &File{Driver: "Driver"}
+	f.AddChannels(
+		&StreamChannel[float32]{Frequency: 1000, Name: "Speed", Unit: "km/h", Reader: &sineReader{n: samples}},
+		&Channel[int16]{Frequency: 10, Name: "Gear", Data: &[]int16{1, 2, 3}},
+	)
+
+	path := filepath.Join(t.TempDir(), "synthetic.ld")
+	fd, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	if err := f.Write(fd); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+// openSynthetic opens a file written by writeSyntheticFile with OpenLazy.
+func openSynthetic(t testing.TB, path string) *LazyFile {
+	t.Helper()
+
+	fd, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fd.Close() })
+
+	lf, err := OpenLazy(fd)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return lf
+}
+
+func TestChannelInto(t *testing.T) {
+	lf, err := OpenLazy(bytes.NewReader(writeBytes(t, twoChannelFile())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		buf     int
+		want    []float64
+		wantN   int
+		wantErr error
+	}{
+		{name: "Speed", buf: 4, want: []float64{1, 2, 3, 4}, wantN: 4},
+		{name: "Speed", buf: 10, want: []float64{1, 2, 3, 4}, wantN: 4},
+		{name: "Gear", buf: 2, want: []float64{1, 2}, wantN: 2},
+		{name: "Speed", buf: 3, wantN: 4, wantErr: io.ErrShortBuffer},
+		{name: "Missing", buf: 4, wantErr: ErrChannelNotFound},
+	}
+
+	for _, tt := range tests {
+		buf := make([]float64, tt.buf)
+		n, err := lf.ChannelInto(tt.name, buf)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s into %d: got error %v, want %v", tt.name, tt.buf, err, tt.wantErr)
+			continue
+		}
+		if n != tt.wantN {
+			t.Errorf("%s into %d: got %d samples, want %d", tt.name, tt.buf, n, tt.wantN)
+		}
+		for i, want := range tt.want {
+			if buf[i] != want {
+				t.Errorf("%s into %d: sample %d = %v, want %v", tt.name, tt.buf, i, buf[i], want)
+			}
+		}
+	}
+}
+
+func TestLazyChannels(t *testing.T) {
+	lf, err := OpenLazy(bytes.NewReader(writeBytes(t, twoChannelFile())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := lf.Channels(), twoChannelFile().ChannelInfos(); !slices.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if lf.Metadata.Driver != "Driver" || lf.Metadata.Channels != nil {
+		t.Errorf("metadata = %+v", lf.Metadata)
+	}
+}
+
+// scaledFile returns a file with a scaled int16 channel, an int32 channel and
+// a second channel named "Pressure".
+func scaledFile() *File {
+	f := &File{}
+	f.AddChannels(
+		&Channel[int16]{Frequency: 10, Name: "Pressure", Shift: 1, Mul: 2, DecPlaces: 1, Data: &[]int16{10, 25}},
+		&Channel[int32]{Frequency: 1, Name: "Counter", Data: &[]int32{1 << 20}},
+		&Channel[float32]{Frequency: 5, Name: "Pressure", Data: &[]float32{99}},
+	)
+	return f
+}
+
+func TestReadChannelsAsFloat64(t *testing.T) {
+	samples, frequencies, err := ReadChannelsAsFloat64(bytes.NewReader(writeBytes(t, scaledFile())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// (raw / 10 + 1) * 2, keeping the first channel named "Pressure"
+	if got := samples["Pressure"]; !slices.Equal(got, []float64{4, 7}) || frequencies["Pressure"] != 10 {
+		t.Errorf("pressure = %v at %d Hz", got, frequencies["Pressure"])
+	}
+	if got := samples["Counter"]; !slices.Equal(got, []float64{1 << 20}) || frequencies["Counter"] != 1 {
+		t.Errorf("counter = %v at %d Hz", got, frequencies["Counter"])
+	}
+	if len(samples) != 2 {
+		t.Errorf("got %d channels, want 2", len(samples))
+	}
+}
+
+func TestReadChannelsAsFloat32(t *testing.T) {
+	samples, err := ReadChannelsAsFloat32(bytes.NewReader(writeBytes(t, scaledFile())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := samples["Pressure"]; !slices.Equal(got, []float32{4, 7}) {
+		t.Errorf("pressure = %v", got)
+	}
+	if got := samples["Counter"]; !slices.Equal(got, []float32{1 << 20}) {
+		t.Errorf("counter = %v", got)
+	}
+	if len(samples) != 2 {
+		t.Errorf("got %d channels, want 2", len(samples))
+	}
+}
+
+func TestLazySynthetic(t *testing.T) {
+	const samples = 100_000
+	lf := openSynthetic(t, writeSyntheticFile(t, samples))
+
+	buf := make([]float64, samples)
+	n, err := lf.ChannelInto("Speed", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != samples {
+		t.Fatalf("got %d samples, want %d", n, samples)
+	}
+	for _, i := range []int{0, 1, samples / 2, samples - 1} {
+		if want := float64(float32(math.Sin(float64(i) / 100))); buf[i] != want {
+			t.Errorf("sample %d = %v, want %v", i, buf[i], want)
+		}
+	}
+}
+
+// TestLazyMemoryBounded checks that the memory used by a LazyFile depends on
+// the channels actually read rather than on the size of the file.
+func TestLazyMemoryBounded(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes a large file")
+	}
+
+	const samples = 4 << 20 // 16 MiB of float32 data
+	path := writeSyntheticFile(t, samples)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	lf := openSynthetic(t, path)
+	buf := make([]float64, 3)
+	if _, err := lf.ChannelInto("Gear", buf); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("opening a %d MiB file and reading a small channel allocated %d bytes", samples*4>>20, allocated)
+	}
+
+	// Reading the large channel allocates the same for both sizes: the raw
+	// samples go through a fixed-size chunk, not a copy of the channel
+	read := func(samples int) uint64 {
+		lf := openSynthetic(t, writeSyntheticFile(t, samples))
+		large := make([]float64, samples)
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		if _, err := lf.ChannelInto("Speed", large); err != nil {
+			t.Fatal(err)
+		}
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	if small, large := read(samples/4), read(samples); small != large {
+		t.Errorf("reading the large channel allocated %d bytes for %d samples and %d bytes for %d", small, samples/4, large, samples)
+	}
+
+	// Reading into a reused buffer allocates nothing
+	large := make([]float64, samples)
+	if _, err := lf.ChannelInto("Speed", large); err != nil {
+		t.Fatal(err)
+	}
+	// Average over enough runs that stray allocations of other goroutines,
+	// such as finalizers left by earlier tests, round down to zero
+	runtime.GC()
+	allocs := testing.AllocsPerRun(20, func() {
+		if _, err := lf.ChannelInto("Speed", large); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("ChannelInto with a reused buffer made %v allocations", allocs)
+	}
+}
+
+func BenchmarkLazyRead(b *testing.B) {
+	const samples = 8 << 20 // 32 MiB of float32 data
+	lf := openSynthetic(b, writeSyntheticFile(b, samples))
+	buf := make([]float64, samples)
+
+	b.SetBytes(samples * 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lf.ChannelInto("Speed", buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
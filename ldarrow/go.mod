@@ -0,0 +1,15 @@
+module github.com/riccardotornesello/motecldparser/ldarrow
+
+go 1.22.1
+
+require (
+	github.com/apache/arrow/go/arrow v0.0.0-20211112161151-bc219186db40
+	github.com/riccardotornesello/motecldparser v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
+)
+
+replace github.com/riccardotornesello/motecldparser => ../
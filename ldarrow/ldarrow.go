@@ -0,0 +1,124 @@
+// Package ldarrow converts MoTeC LD files to Apache Arrow records.
+//
+// It is a separate module, so programs that only read and write LD files do
+// not depend on Arrow:
+//
+//	go get github.com/riccardotornesello/motecldparser/ldarrow
+package ldarrow
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	"github.com/riccardotornesello/motecldparser"
+)
+
+// FromFile converts the channels of a file to an Arrow record, one column per
+// channel.
+//
+// Arrow columns share a common length, so every channel is resampled to the
+// highest frequency of the file (see Channel.Resample) and becomes a column
+// of the same type: float32, int16 or int32. Channels shorter than the
+// longest one are padded with nulls. Each field carries the channel's unit
+// and original frequency as "unit" and "frequency" metadata, and the schema
+// carries the base frequency as "frequency".
+//
+// The record must be released by the caller. Returns
+// motecldparser.ErrUnknownSize if the file contains a StreamChannel, whose
+// data is not held in memory.
+//
+// Example:
+//
+//	record, err := ldarrow.FromFile(file)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer record.Release()
+func FromFile(f *motecldparser.File) (array.Record, error) {
+	// Resample first, so the length of the columns is known
+	var base uint16
+	for _, c := range f.AnyChannels() {
+		base = max(base, c.ChannelFrequency())
+	}
+
+	var resampled []motecldparser.AnyChannel
+	var frequencies []uint16
+	rows := 0
+	for _, channel := range f.Channels {
+		var c motecldparser.AnyChannel
+		switch ch := channel.(type) {
+		case *motecldparser.Channel[float32]:
+			c = ch.Resample(base)
+		case *motecldparser.Channel[int16]:
+			c = ch.Resample(base)
+		case *motecldparser.Channel[int32]:
+			c = ch.Resample(base)
+		case motecldparser.AnyChannel:
+			return nil, fmt.Errorf("%w: channel %q is a stream", motecldparser.ErrUnknownSize, ch.ChannelName())
+		default:
+			continue
+		}
+		resampled = append(resampled, c)
+		frequencies = append(frequencies, channel.(motecldparser.AnyChannel).ChannelFrequency())
+		rows = max(rows, c.SampleCount())
+	}
+
+	mem := memory.NewGoAllocator()
+	fields := make([]arrow.Field, len(resampled))
+	columns := make([]array.Interface, len(resampled))
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+
+	for i, channel := range resampled {
+		fields[i] = arrow.Field{
+			Name:     channel.ChannelName(),
+			Nullable: channel.SampleCount() < rows,
+			Metadata: arrow.NewMetadata(
+				[]string{"unit", "frequency"},
+				[]string{channel.ChannelUnit(), strconv.Itoa(int(frequencies[i]))},
+			),
+		}
+
+		switch c := channel.(type) {
+		case *motecldparser.Channel[float32]:
+			b := array.NewFloat32Builder(mem)
+			fields[i].Type = arrow.PrimitiveTypes.Float32
+			columns[i] = buildColumn(b, b.AppendValues, b.AppendNull, c, rows)
+		case *motecldparser.Channel[int16]:
+			b := array.NewInt16Builder(mem)
+			fields[i].Type = arrow.PrimitiveTypes.Int16
+			columns[i] = buildColumn(b, b.AppendValues, b.AppendNull, c, rows)
+		case *motecldparser.Channel[int32]:
+			b := array.NewInt32Builder(mem)
+			fields[i].Type = arrow.PrimitiveTypes.Int32
+			columns[i] = buildColumn(b, b.AppendValues, b.AppendNull, c, rows)
+		}
+	}
+
+	metadata := arrow.NewMetadata([]string{"frequency"}, []string{strconv.Itoa(int(base))})
+	schema := arrow.NewSchema(fields, &metadata)
+
+	// The record retains the columns, which are released by the deferred call
+	return array.NewRecord(schema, columns, int64(rows)), nil
+}
+
+// buildColumn builds an Arrow column holding the samples of a channel,
+// padded with nulls to rows values.
+func buildColumn[T float32 | int16 | int32](b array.Builder, appendValues func([]T, []bool), appendNull func(), c *motecldparser.Channel[T], rows int) array.Interface {
+	defer b.Release()
+
+	if c.Data != nil {
+		appendValues(*c.Data, nil)
+	}
+	for n := c.SampleCount(); n < rows; n++ {
+		appendNull()
+	}
+	return b.NewArray()
+}
@@ -0,0 +1,97 @@
+package ldarrow
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+
+	"github.com/riccardotornesello/motecldparser"
+)
+
+func TestFromFile(t *testing.T) {
+	f := &motecldparser.File{}
+	f.AddChannels(
+		&motecldparser.Channel[float32]{Frequency: 10, Name: "Speed", Unit: "km/h", Data: &[]float32{1, 2, 3, 4}},
+		&motecldparser.Channel[int16]{Frequency: 5, Name: "Gear", Data: &[]int16{1, 2}},
+	)
+
+	record, err := FromFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer record.Release()
+
+	if record.NumCols() != 2 || record.NumRows() != 4 {
+		t.Fatalf("got %d columns and %d rows, want 2 and 4", record.NumCols(), record.NumRows())
+	}
+
+	schema := record.Schema()
+	if freq := metadataValue(schema.Metadata(), "frequency"); freq != "10" {
+		t.Errorf("schema frequency = %q, want 10", freq)
+	}
+
+	speed := schema.Field(0)
+	if speed.Name != "Speed" || speed.Type != arrow.PrimitiveTypes.Float32 || speed.Nullable {
+		t.Errorf("speed field = %+v", speed)
+	}
+	if unit := metadataValue(speed.Metadata, "unit"); unit != "km/h" {
+		t.Errorf("speed unit = %q", unit)
+	}
+	if got := record.Column(0).(*array.Float32).Float32Values(); !slices.Equal(got, []float32{1, 2, 3, 4}) {
+		t.Errorf("speed = %v", got)
+	}
+
+	gear := schema.Field(1)
+	if gear.Name != "Gear" || gear.Type != arrow.PrimitiveTypes.Int16 {
+		t.Errorf("gear field = %+v", gear)
+	}
+	if freq := metadataValue(gear.Metadata, "frequency"); freq != "5" {
+		t.Errorf("gear frequency = %q, want the original 5", freq)
+	}
+	if got := record.Column(1).Len(); got != 4 {
+		t.Errorf("gear has %d values, want 4", got)
+	}
+}
+
+// metadataValue returns the value of key in md, or "" if missing.
+func metadataValue(md arrow.Metadata, key string) string {
+	if i := md.FindKey(key); i >= 0 {
+		return md.Values()[i]
+	}
+	return ""
+}
+
+func TestFromFilePadsWithNulls(t *testing.T) {
+	f := &motecldparser.File{}
+	f.AddChannels(
+		&motecldparser.Channel[int32]{Frequency: 10, Name: "Long", Data: &[]int32{1, 2, 3}},
+		&motecldparser.Channel[int32]{Frequency: 10, Name: "Short", Data: &[]int32{7}},
+	)
+
+	record, err := FromFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer record.Release()
+
+	if !record.Schema().Field(1).Nullable {
+		t.Error("short column not nullable")
+	}
+	short := record.Column(1)
+	if short.NullN() != 2 || short.IsNull(0) || !short.IsNull(2) {
+		t.Errorf("short column has %d nulls", short.NullN())
+	}
+}
+
+func TestFromFileStream(t *testing.T) {
+	f := &motecldparser.File{}
+	f.AddChannels(&motecldparser.StreamChannel[float32]{Frequency: 10, Name: "Speed", Reader: strings.NewReader("")})
+
+	if _, err := FromFile(f); !errors.Is(err, motecldparser.ErrUnknownSize) {
+		t.Errorf("got %v, want ErrUnknownSize", err)
+	}
+}
@@ -9,6 +9,13 @@ package ldfile
 // Channels are stored in a linked list structure, with PreviousMetaPointer
 // and NextMetaPointer forming the links. The last channel has NextMetaPointer
 // set to 0, and the first channel has PreviousMetaPointer set to 0.
+//
+// The trailing reserved region has no known meaning and is always written as
+// zeros. In particular it does not hold a channel comment or description, so
+// the LD format provides no place to store one. Nor does it hold any known
+// pro logging attribute, such as a sample down-rate or a per-channel logging
+// enable: the only pro logging value identified in the format is
+// LdFileHead.EnableProLogging.
 type LdFileChannelMeta struct {
 	PreviousMetaPointer uint32
 	NextMetaPointer     uint32
@@ -27,3 +34,14 @@ type LdFileChannelMeta struct {
 	Unit                [12]byte
 	_                   [40]byte // (40 bytes for ACC, 32 bytes for acti)
 }
+
+// Sizes in bytes of the known channel metadata layouts.
+//
+// LdFileChannelMeta describes the ACC layout. The acti layout has the same
+// fields but a 32-byte trailing reserved region, so consecutive metadata
+// blocks are closer together. Readers only need the first ChannelMetaSizeActi
+// bytes of either layout to decode every field.
+const (
+	ChannelMetaSize     = 124 // ACC layout, 40-byte trailing region
+	ChannelMetaSizeActi = 116 // acti layout, 32-byte trailing region
+)
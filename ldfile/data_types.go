@@ -4,6 +4,10 @@ package ldfile
 //
 // The DataType field specifies the type of data (float, int16, int32), and
 // DataTypeLength specifies the size in bytes of each data point.
+//
+// No 3-byte integer type is known to be supported by MoTeC software. Signals
+// with 24 bits of resolution, common on CAN buses, should be promoted and
+// stored as DataTypeInt32.
 type DataType struct {
 	DataType       uint16 // Type identifier (0x07 for float, 0x03 for int16, 0x05 for int32)
 	DataTypeLength uint16 // Size in bytes (2 or 4)
@@ -9,5 +9,5 @@ type LdFileEvent struct {
 	Name         [64]byte
 	Session      [64]byte
 	Comment      [1024]byte
-	VenuePointer uint16
+	VenuePointer uint16 // 16-bit offset: the venue block must be within the first 64 KiB
 }
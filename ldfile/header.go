@@ -13,6 +13,11 @@ package ldfile
 //
 // The header contains several unknown fields that are required for compatibility
 // with MoTeC software but whose exact purpose is not documented.
+//
+// None of the reserved regions has an identified meaning related to the
+// logger, such as its memory capacity or the storage used by the session, so
+// no such field can be read or written; the regions are always written as
+// zeros.
 type LdFileHead struct {
 	LDMarker            uint32 // 0x40
 	_                   [4]byte
@@ -0,0 +1,20 @@
+package ldfile
+
+// Sizes in bytes of the fixed blocks at the start of a file, as encoded by
+// encoding/binary.
+const (
+	HeadSize    = 1762 // LdFileHead
+	EventSize   = 1154 // LdFileEvent
+	VenueSize   = 1100 // LdFileVenue
+	VehicleSize = 260  // LdFileVehicle
+)
+
+// LdFileEvent.VenuePointer and LdFileVenue.VehiclePointer are only 16 bits
+// wide. They can only address the venue and vehicle blocks because those
+// immediately follow the header and the event block, within the first 64 KiB
+// of the file. The conversions below fail to compile if the blocks grow past
+// that limit.
+const (
+	_ = uint16(HeadSize + EventSize)             // Venue pointer
+	_ = uint16(HeadSize + EventSize + VenueSize) // Vehicle pointer
+)
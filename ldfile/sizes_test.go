@@ -0,0 +1,26 @@
+package ldfile
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestBlockSizes(t *testing.T) {
+	tests := []struct {
+		name  string
+		block any
+		want  int
+	}{
+		{name: "LdFileHead", block: LdFileHead{}, want: HeadSize},
+		{name: "LdFileEvent", block: LdFileEvent{}, want: EventSize},
+		{name: "LdFileVenue", block: LdFileVenue{}, want: VenueSize},
+		{name: "LdFileVehicle", block: LdFileVehicle{}, want: VehicleSize},
+		{name: "LdFileChannelMeta", block: LdFileChannelMeta{}, want: ChannelMetaSize},
+	}
+
+	for _, tt := range tests {
+		if got := binary.Size(tt.block); got != tt.want {
+			t.Errorf("%s is %d bytes, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package ldfile
+
+import (
+	"bytes"
+	"time"
+)
+
+// ParseHeadTime decodes the Date and Time fields of an LdFileHead.
+//
+// The fields hold null-padded "dd/MM/yyyy" and "HH:mm:ss" strings. The result
+// is in UTC, as the file does not record a time zone.
+func ParseHeadTime(date, timeField [16]byte) (time.Time, error) {
+	return time.Parse("02/01/2006 15:04:05", trimNull(date[:])+" "+trimNull(timeField[:]))
+}
+
+// trimNull returns the string stored in a null-padded byte array.
+func trimNull(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
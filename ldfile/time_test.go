@@ -0,0 +1,24 @@
+package ldfile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHeadTime(t *testing.T) {
+	var date, timeField [16]byte
+	copy(date[:], "17/05/2024")
+	copy(timeField[:], "14:30:05")
+
+	got, err := ParseHeadTime(date, timeField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 17, 14, 30, 5, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := ParseHeadTime([16]byte{}, timeField); err == nil {
+		t.Error("empty date: got nil error")
+	}
+}
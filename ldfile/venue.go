@@ -8,5 +8,5 @@ package ldfile
 type LdFileVenue struct {
 	Name           [64]byte
 	_              [1034]byte
-	VehiclePointer uint16
+	VehiclePointer uint16 // 16-bit offset: the vehicle block must be within the first 64 KiB
 }
@@ -0,0 +1,158 @@
+package motecldparser
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ErrMarkerOutOfRange is reported when a marker of an .ldx file lies outside
+// the session of its .ld file.
+var ErrMarkerOutOfRange = errors.New("motecldparser: marker outside the session")
+
+// ldxFile is the part of the .ldx companion file holding the markers.
+type ldxFile struct {
+	XMLName       xml.Name   `xml:"LDXFile"`
+	Locale        string     `xml:"Locale,attr,omitempty"`
+	DefaultLocale string     `xml:"DefaultLocale,attr,omitempty"`
+	Version       string     `xml:"Version,attr,omitempty"`
+	Groups        []ldxGroup `xml:"Layers>Layer>MarkerBlock>MarkerGroup"`
+}
+
+// ldxGroup is a named group of markers, such as the lap beacons.
+type ldxGroup struct {
+	Name    string      `xml:"Name,attr"`
+	Index   int         `xml:"Index,attr"`
+	Markers []ldxMarker `xml:"Marker"`
+}
+
+// ldxMarker is a marker of an .ldx file. Time is in microseconds from the
+// start of the session.
+type ldxMarker struct {
+	Version   int     `xml:"Version,attr"`
+	ClassName string  `xml:"ClassName,attr"`
+	Name      string  `xml:"Name,attr"`
+	Flags     int     `xml:"Flags,attr"`
+	Time      ldxTime `xml:"Time,attr"`
+}
+
+// ldxTime is a marker time in microseconds. i2 writes it in fixed-point
+// notation (e.g. "75660000.000000"), which encoding/xml does not produce for
+// a float64.
+type ldxTime float64
+
+// MarshalXMLAttr formats the time like i2 does.
+func (t ldxTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: strconv.FormatFloat(float64(t), 'f', 6, 64)}, nil
+}
+
+// ValidatePair checks that an .ldx file matches its .ld file.
+//
+// The .ldx file holds the beacons, laps and other markers of the session, as
+// times from its start; it does not reference channels. Every marker must
+// therefore lie within the session recorded in the .ld file, whose duration is
+// that of its longest channel. Markers outside it, the sign of a pair of files
+// from different sessions, are reported as ErrMarkerOutOfRange, joined with
+// errors.Join.
+//
+// Only the .ld header and channel metadata are read, as with Catalog.
+func ValidatePair(ld io.ReaderAt, ldx io.Reader) error {
+	entry, err := Catalog(ld)
+	if err != nil {
+		return fmt.Errorf("read ld: %w", err)
+	}
+
+	var doc ldxFile
+	if err := xml.NewDecoder(ldx).Decode(&doc); err != nil {
+		return fmt.Errorf("read ldx: %w", err)
+	}
+
+	duration := time.Duration(entry.Duration * float64(time.Second))
+
+	var errs []error
+	for _, group := range doc.Groups {
+		for _, marker := range group.Markers {
+			at := time.Duration(marker.Time) * time.Microsecond
+			if at < 0 || at > duration {
+				errs = append(errs, fmt.Errorf("%w: %s marker %q at %v, session lasts %v", ErrMarkerOutOfRange, group.Name, marker.Name, at, duration))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WriteSimpleLdx writes an .ldx file placing a beacon marker at each of the
+// given times from the start of the session.
+//
+// It is a lightweight alternative to lap detection for marking points of
+// interest, such as fixed intervals or external events. Each time is rounded
+// to the nearest sample of the fastest channel, so markers line up with
+// logged data, and written as a manual beacon ("Manual.1", "Manual.2", ...)
+// that i2 shows and uses to split laps. Markers are written in the given
+// order.
+//
+// Returns ErrInvalidFrequency if no channel has a frequency, and
+// ErrMarkerOutOfRange, joined with errors.Join, for times before the start or
+// after the end of the longest channel. Nothing is written on error.
+//
+// Example:
+//
+//	fd, _ := os.Create("telemetry.ldx")
+//	err := file.WriteSimpleLdx(fd, []time.Duration{90 * time.Second, 180 * time.Second})
+func (f *File) WriteSimpleLdx(w io.Writer, markers []time.Duration) error {
+	var base uint16
+	var duration time.Duration
+	for _, info := range f.ChannelInfos() {
+		base = max(base, info.Frequency)
+		duration = max(duration, time.Duration(info.Duration*float64(time.Second)))
+	}
+
+	if base == 0 {
+		return fmt.Errorf("%w: no channel to place the markers on", ErrInvalidFrequency)
+	}
+
+	group := ldxGroup{Name: "Beacons", Index: 3}
+	var errs []error
+	for i, at := range markers {
+		if at < 0 || at > duration {
+			errs = append(errs, fmt.Errorf("%w: marker %d at %v, session lasts %v", ErrMarkerOutOfRange, i, at, duration))
+			continue
+		}
+
+		sample := math.Round(at.Seconds() * float64(base))
+		group.Markers = append(group.Markers, ldxMarker{
+			Version:   100,
+			ClassName: "BCN",
+			Name:      fmt.Sprintf("Manual.%d", i+1),
+			Flags:     77,
+			Time:      ldxTime(sample * 1e6 / float64(base)),
+		})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	doc := ldxFile{
+		Locale:        "English_Australia.1252",
+		DefaultLocale: "C",
+		Version:       "1.6",
+		Groups:        []ldxGroup{group},
+	}
+
+	out, err := xml.MarshalIndent(doc, "", " ")
+	if err != nil {
+		return fmt.Errorf("encode ldx: %w", err)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+string(out)+"\n"); err != nil {
+		return fmt.Errorf("write ldx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteSimpleLdx(t *testing.T) {
+	f := twoChannelFile() // 0.4 s at 10 Hz
+
+	var buf bytes.Buffer
+	if err := f.WriteSimpleLdx(&buf, []time.Duration{260 * time.Millisecond, 0, 400 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	ldx := buf.String()
+
+	for _, want := range []string{
+		`<?xml`,
+		`<MarkerGroup Name="Beacons" Index="3">`,
+		`Name="Manual.1" Flags="77" Time="300000.000000"`,
+		`Name="Manual.2" Flags="77" Time="0.000000"`,
+		`Name="Manual.3" Flags="77" Time="400000.000000"`,
+	} {
+		if !strings.Contains(ldx, want) {
+			t.Errorf("missing %s in:\n%s", want, ldx)
+		}
+	}
+
+	if err := ValidatePair(bytes.NewReader(writeBytes(t, f)), strings.NewReader(ldx)); err != nil {
+		t.Errorf("ValidatePair: %v", err)
+	}
+}
+
+func TestWriteSimpleLdxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    *File
+		markers []time.Duration
+		want    error
+	}{
+		{name: "no channels", file: &File{}, markers: []time.Duration{0}, want: ErrInvalidFrequency},
+		{name: "after the end", file: twoChannelFile(), markers: []time.Duration{0, time.Second}, want: ErrMarkerOutOfRange},
+		{name: "negative", file: twoChannelFile(), markers: []time.Duration{-time.Millisecond}, want: ErrMarkerOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.file.WriteSimpleLdx(&buf, tt.markers); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+			if buf.Len() > 0 {
+				t.Errorf("wrote %d bytes on error", buf.Len())
+			}
+		})
+	}
+}
+
+func TestValidatePair(t *testing.T) {
+	ld := writeBytes(t, twoChannelFile()) // 0.4 s
+	ldx := func(times ...string) string {
+		var markers strings.Builder
+		for _, at := range times {
+			markers.WriteString(`<Marker Version="100" ClassName="BCN" Name="Manual" Flags="77" Time="` + at + `"/>`)
+		}
+		return `<LDXFile><Layers><Layer><MarkerBlock><MarkerGroup Name="Beacons" Index="3">` +
+			markers.String() + `</MarkerGroup></MarkerBlock></Layer></Layers></LDXFile>`
+	}
+
+	tests := []struct {
+		name string
+		ldx  string
+		want error
+	}{
+		{name: "inside", ldx: ldx("0.000000", "400000.000000")},
+		{name: "no markers", ldx: `<LDXFile/>`},
+		{name: "after the end", ldx: ldx("100000", "500000"), want: ErrMarkerOutOfRange},
+		{name: "before the start", ldx: ldx("-1"), want: ErrMarkerOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePair(bytes.NewReader(ld), strings.NewReader(tt.ldx)); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := ValidatePair(bytes.NewReader(ld), strings.NewReader("<LDXFile>")); err == nil {
+		t.Error("truncated ldx: got nil error")
+	}
+	if err := ValidatePair(bytes.NewReader([]byte("short")), strings.NewReader(ldx())); err == nil {
+		t.Error("invalid ld: got nil error")
+	}
+}
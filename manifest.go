@@ -0,0 +1,142 @@
+package motecldparser
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ChannelInfo summarizes a channel without its data.
+type ChannelInfo struct {
+	Name      string  `json:"name"`
+	ShortName string  `json:"shortName,omitempty"`
+	Unit      string  `json:"unit,omitempty"`
+	Frequency uint16  `json:"frequency"`       // Sampling frequency in Hz
+	Samples   int     `json:"samples"`         // Number of samples
+	Duration  float64 `json:"durationSeconds"` // Samples / Frequency, in seconds
+}
+
+// ChannelInfos returns a summary of every channel of the file.
+//
+// The sample count of a StreamChannel is only known after the file has been
+// written and is zero before that.
+func (f *File) ChannelInfos() []ChannelInfo {
+	channels := f.AnyChannels()
+	infos := make([]ChannelInfo, len(channels))
+	for i, c := range channels {
+		infos[i] = ChannelInfo{
+			Name:      c.ChannelName(),
+			ShortName: c.ChannelShortName(),
+			Unit:      c.ChannelUnit(),
+			Frequency: c.ChannelFrequency(),
+			Samples:   c.SampleCount(),
+		}
+		if c.ChannelFrequency() > 0 {
+			infos[i].Duration = float64(c.SampleCount()) / float64(c.ChannelFrequency())
+		}
+	}
+	return infos
+}
+
+// manifest is the JSON document written by File.WriteManifest.
+type manifest struct {
+	Time         time.Time `json:"time"`
+	Driver       string    `json:"driver,omitempty"`
+	Vehicle      string    `json:"vehicle,omitempty"`
+	Venue        string    `json:"venue,omitempty"`
+	ShortComment string    `json:"shortComment,omitempty"`
+
+	EventName    string `json:"eventName,omitempty"`
+	EventSession string `json:"eventSession,omitempty"`
+	EventComment string `json:"eventComment,omitempty"`
+
+	VehicleId      string `json:"vehicleId,omitempty"`
+	VehicleWeight  uint32 `json:"vehicleWeight,omitempty"`
+	VehicleType    string `json:"vehicleType,omitempty"`
+	VehicleComment string `json:"vehicleComment,omitempty"`
+
+	Channels []ChannelInfo `json:"channels"`
+}
+
+// WriteManifest writes a JSON description of the file to w.
+//
+// The manifest holds the session, event and vehicle metadata and a
+// ChannelInfo entry per channel (name, unit, frequency, sample count and
+// duration). Written next to the .ld file, it gives downstream tools an index
+// of its contents without parsing the binary format.
+//
+// Example output:
+//
+//	{
+//	  "time": "2024-03-04T10:00:00Z",
+//	  "driver": "John Doe",
+//	  "channels": [
+//	    {"name": "Speed", "unit": "km/h", "frequency": 100, "samples": 6000, "durationSeconds": 60}
+//	  ]
+//	}
+func (f *File) WriteManifest(w io.Writer) error {
+	m := manifest{
+		Time:         f.Time,
+		Driver:       f.Driver,
+		Vehicle:      f.Vehicle,
+		Venue:        f.Venue,
+		ShortComment: f.ShortComment,
+
+		EventName:    f.EventName,
+		EventSession: f.EventSession,
+		EventComment: f.EventComment,
+
+		VehicleId:      f.VehicleId,
+		VehicleWeight:  f.VehicleWeight,
+		VehicleType:    f.VehicleType,
+		VehicleComment: f.VehicleComment,
+
+		Channels: f.ChannelInfos(),
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// CatalogEntry is a short description of a file, suitable for listing many
+// files.
+type CatalogEntry struct {
+	Time     time.Time `json:"time"`
+	Driver   string    `json:"driver,omitempty"`
+	Vehicle  string    `json:"vehicle,omitempty"`
+	Venue    string    `json:"venue,omitempty"`
+	Duration float64   `json:"durationSeconds"` // Duration of the longest channel, in seconds
+	Channels []string  `json:"channels"`        // Channel names, in file order
+}
+
+// Catalog returns a short description of a MoTeC LD file.
+//
+// Only the header and the channel metadata are read, as with OpenLazy, so the
+// cost does not depend on the amount of channel data. The duration is that of
+// the longest channel.
+//
+// Example:
+//
+//	entry, err := motecldparser.Catalog(fd)
+//	fmt.Printf("%s at %s, %.0f s, %d channels\n", entry.Driver, entry.Venue, entry.Duration, len(entry.Channels))
+func Catalog(r io.ReaderAt) (CatalogEntry, error) {
+	lf, err := OpenLazy(r)
+	if err != nil {
+		return CatalogEntry{}, err
+	}
+
+	entry := CatalogEntry{
+		Time:     lf.Metadata.Time,
+		Driver:   lf.Metadata.Driver,
+		Vehicle:  lf.Metadata.Vehicle,
+		Venue:    lf.Metadata.Venue,
+		Channels: []string{},
+	}
+	for _, info := range lf.Channels() {
+		entry.Channels = append(entry.Channels, info.Name)
+		entry.Duration = max(entry.Duration, info.Duration)
+	}
+
+	return entry, nil
+}
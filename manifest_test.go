@@ -0,0 +1,76 @@
+package motecldparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChannelInfos(t *testing.T) {
+	f := twoChannelFile()
+	f.AddChannels(&Channel[int32]{Name: "No frequency", Data: &[]int32{1}})
+
+	want := []ChannelInfo{
+		{Name: "Speed", Unit: "km/h", Frequency: 10, Samples: 4, Duration: 0.4},
+		{Name: "Gear", Frequency: 5, Samples: 2, Duration: 0.4},
+		{Name: "No frequency", Samples: 1},
+	}
+	if got := f.ChannelInfos(); !slices.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	f := twoChannelFile()
+	f.Time = time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := f.WriteManifest(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["time"] != "2024-03-04T10:00:00Z" || got["driver"] != "Driver" || got["venue"] != "Venue" {
+		t.Errorf("metadata = %v", got)
+	}
+	if _, ok := got["vehicle"]; ok {
+		t.Error("empty vehicle not omitted")
+	}
+	channels, _ := got["channels"].([]any)
+	if len(channels) != 2 {
+		t.Fatalf("channels = %v", got["channels"])
+	}
+	if speed := channels[0].(map[string]any); speed["name"] != "Speed" || speed["frequency"] != 10.0 || speed["durationSeconds"] != 0.4 {
+		t.Errorf("speed = %v", speed)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	f := twoChannelFile()
+	f.Time = time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	f.AddChannels(&Channel[int16]{Frequency: 1, Name: "Long", Data: &[]int16{1, 2, 3}})
+
+	entry, err := Catalog(bytes.NewReader(writeBytes(t, f)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Driver != "Driver" || entry.Venue != "Venue" || !entry.Time.Equal(f.Time) || entry.Duration != 3 {
+		t.Errorf("entry = %+v", entry)
+	}
+	if !slices.Equal(entry.Channels, []string{"Speed", "Gear", "Long"}) {
+		t.Errorf("channels = %v", entry.Channels)
+	}
+
+	empty, err := Catalog(bytes.NewReader(writeBytes(t, &File{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Channels == nil || len(empty.Channels) != 0 {
+		t.Errorf("no channels: got %#v, want an empty list", empty.Channels)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package motecldparser
+
+// WriteMmap writes the file to path.
+//
+// Memory mapping is not supported on this platform, so the file is written
+// with File.Write.
+func (f *File) WriteMmap(path string) error {
+	return writeFile(f, path)
+}
@@ -0,0 +1,51 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package motecldparser
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// WriteMmap writes the file to path through a memory mapping.
+//
+// The output file is created (or truncated) with its final size, mapped into
+// memory and every section is written directly into the mapping. This avoids
+// the seeks and small writes of File.Write, which is noticeably faster for
+// very large files.
+//
+// Memory mapping is only used on platforms that support it and when the size
+// of the file is known in advance (see File.Size). Otherwise WriteMmap falls
+// back to File.Write.
+func (f *File) WriteMmap(path string) error {
+	size, err := f.Size()
+	if err != nil {
+		return writeFile(f, path)
+	}
+
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	if err := fd.Truncate(size); err != nil {
+		return err
+	}
+
+	data, err := syscall.Mmap(int(fd.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("map %s: %w", path, err)
+	}
+
+	writeErr := f.write(&writeBuffer{data: data[:0], fixed: true})
+	if err := syscall.Munmap(data); err != nil && writeErr == nil {
+		writeErr = fmt.Errorf("unmap %s: %w", path, err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return fd.Close()
+}
@@ -0,0 +1,242 @@
+package motecldparser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+// ErrFileTooLarge is returned when a file has more channels or data than the
+// LD format can address.
+var ErrFileTooLarge = errors.New("motecldparser: file too large for the LD format")
+
+// maxChannels is the number of channels whose IDs, counted from 0x2EE1, fit
+// the uint16 ChannelId field.
+const maxChannels = math.MaxUint16 - 0x2EE1 + 1
+
+// WritePlan describes where each section of a file is written.
+//
+// All pointers are byte offsets from the start of the file.
+type WritePlan struct {
+	EventPointer        int64 // Offset of the event block (right after the header)
+	VenuePointer        int64 // Offset of the venue block
+	VehiclePointer      int64 // Offset of the vehicle block
+	ChannelsMetaPointer int64 // Offset of the first channel metadata block
+	ChannelsDataPointer int64 // Offset of the first channel's data
+
+	Channels []ChannelPlan // Layout of each entry of File.Channels, in order
+
+	Size int64 // Total size of the file
+}
+
+// ChannelPlan describes where a channel is written.
+type ChannelPlan struct {
+	MetaPointer int64 // Offset of the channel metadata block
+	DataPointer int64 // Offset of the channel data
+	DataLength  int   // Number of samples
+	DataSize    int64 // Size of the channel data in bytes
+}
+
+// Plan returns the layout File.Write will use for the file.
+//
+// The plan is computed from the current channels without writing anything.
+// After a successful write, File.LastWritePlan holds the layout actually used,
+// which equals the plan computed beforehand.
+//
+// Returns ErrUnknownSize if the file contains a StreamChannel, whose length is
+// only known once it has been written, and ErrFileTooLarge if the layout does
+// not fit the format (see checkAddressing).
+func (f *File) Plan() (*WritePlan, error) {
+	plan := f.planSections()
+	if err := plan.checkAddressing(); err != nil {
+		return nil, err
+	}
+
+	dataPointer := plan.ChannelsDataPointer
+	for i, channel := range f.Channels {
+		if err := checkDataPointer(i, dataPointer); err != nil {
+			return nil, err
+		}
+		plan.Channels[i].DataPointer = dataPointer
+
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		spec, ok := c.spec()
+		if !ok {
+			return nil, ErrUnknownSize
+		}
+
+		plan.Channels[i].DataLength = spec.SampleCount
+		plan.Channels[i].DataSize = int64(spec.SampleCount) * int64(spec.DataTypeLength)
+		dataPointer += plan.Channels[i].DataSize
+	}
+
+	plan.Size = dataPointer
+	if err := plan.check(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// planSections computes the offsets of the fixed sections and of the channel
+// metadata blocks. Channel data pointers and the file size are left for the
+// caller to fill in.
+func (f *File) planSections() *WritePlan {
+	plan := &WritePlan{
+		EventPointer: headSize,
+		Channels:     make([]ChannelPlan, len(f.Channels)),
+	}
+	plan.VenuePointer = plan.EventPointer + eventSize
+	plan.VehiclePointer = plan.VenuePointer + venueSize
+	plan.ChannelsMetaPointer = plan.VehiclePointer + vehicleSize
+	plan.ChannelsDataPointer = plan.ChannelsMetaPointer + channelMetaSize*int64(len(f.Channels))
+
+	for i := range plan.Channels {
+		plan.Channels[i].MetaPointer = plan.ChannelsMetaPointer + channelMetaSize*int64(i)
+	}
+
+	return plan
+}
+
+// checkAddressing verifies that the channel IDs and the channel metadata
+// region fit the fixed-width fields of the format. The channels are linked by
+// uint32 pointers and numbered by uint16 IDs, so the metadata region and the
+// start of every channel's data must lie within the first 4 GiB of the file.
+func (p *WritePlan) checkAddressing() error {
+	if len(p.Channels) > maxChannels {
+		return fmt.Errorf("%w: %d channels, max %d", ErrFileTooLarge, len(p.Channels), maxChannels)
+	}
+
+	if p.ChannelsDataPointer > math.MaxUint32 {
+		return fmt.Errorf("%w: channel metadata ends at byte %d, past the uint32 pointer range", ErrFileTooLarge, p.ChannelsDataPointer)
+	}
+
+	return nil
+}
+
+// checkDataPointer verifies that the data of the i-th channel starts at an
+// offset the uint32 DataPointer field can hold.
+func checkDataPointer(i int, pointer int64) error {
+	if pointer > math.MaxUint32 {
+		return fmt.Errorf("%w: channel %d data would start at byte %d, past the uint32 pointer range", ErrFileTooLarge, i, pointer)
+	}
+	return nil
+}
+
+// Section is a region of a serialized file.
+type Section struct {
+	Offset int64  // Offset of the region in the file
+	Data   []byte // Contents of the region
+}
+
+// Sections serializes the file and splits it into the metadata region and one
+// data region per channel, so they can be stored separately.
+//
+// The metadata region starts at offset 0 and holds the header, the event,
+// venue and vehicle blocks and all channel metadata. The data sections are in
+// the order of File.Channels; entries that are not channels get an empty
+// section. Writing every section at its offset, in any order, reassembles the
+// file written by File.Write.
+//
+// The whole file is built in memory, like WriteTo. File.LastWritePlan is set
+// as after a write.
+//
+// Example:
+//
+//	metadata, data, err := file.Sections()
+//	// store metadata in a database and each data section as a blob ...
+//	out.WriteAt(metadata.Data, metadata.Offset)
+//	for _, section := range data {
+//	    out.WriteAt(section.Data, section.Offset)
+//	}
+func (f *File) Sections() (Section, []Section, error) {
+	buf := &writeBuffer{}
+	if err := f.write(buf); err != nil {
+		return Section{}, nil, err
+	}
+
+	plan := f.LastWritePlan
+	metadata := Section{Offset: 0, Data: buf.data[:plan.ChannelsDataPointer]}
+
+	data := make([]Section, len(plan.Channels))
+	for i, channel := range plan.Channels {
+		data[i] = Section{
+			Offset: channel.DataPointer,
+			Data:   buf.data[channel.DataPointer : channel.DataPointer+channel.DataSize],
+		}
+	}
+
+	return metadata, data, nil
+}
+
+// check verifies that the sections of the plan follow each other without
+// gaps or overlaps, guarding the pointer arithmetic of planSections and write.
+// A failure is an internal error of the package.
+func (p *WritePlan) check() error {
+	type expectation struct {
+		name          string
+		got, expected int64
+	}
+
+	expectations := []expectation{
+		{"header size", headSize, ldfile.HeadSize},
+		{"event size", eventSize, ldfile.EventSize},
+		{"venue size", venueSize, ldfile.VenueSize},
+		{"vehicle size", vehicleSize, ldfile.VehicleSize},
+		{"event pointer", p.EventPointer, headSize},
+		{"venue pointer", p.VenuePointer, p.EventPointer + eventSize},
+		{"vehicle pointer", p.VehiclePointer, p.VenuePointer + venueSize},
+		{"channel metadata pointer", p.ChannelsMetaPointer, p.VehiclePointer + vehicleSize},
+		{"channel data pointer", p.ChannelsDataPointer, p.ChannelsMetaPointer + channelMetaSize*int64(len(p.Channels))},
+	}
+
+	dataPointer := p.ChannelsDataPointer
+	for i, channel := range p.Channels {
+		expectations = append(expectations,
+			expectation{fmt.Sprintf("channel %d metadata pointer", i), channel.MetaPointer, p.ChannelsMetaPointer + channelMetaSize*int64(i)},
+			expectation{fmt.Sprintf("channel %d data pointer", i), channel.DataPointer, dataPointer},
+		)
+		dataPointer += channel.DataSize
+	}
+	expectations = append(expectations, expectation{"file size", p.Size, dataPointer})
+
+	for _, e := range expectations {
+		if e.got != e.expected {
+			return fmt.Errorf("motecldparser: internal error: %s is %d, expected %d", e.name, e.got, e.expected)
+		}
+	}
+
+	// Independently of the order above, no two data regions may share bytes.
+	// Empty channels take no space and may share their pointer with the next
+	// channel.
+	order := make([]int, 0, len(p.Channels))
+	for i, channel := range p.Channels {
+		if channel.DataSize > 0 {
+			order = append(order, i)
+		}
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Compare(p.Channels[a].DataPointer, p.Channels[b].DataPointer)
+	})
+
+	end, last := p.ChannelsDataPointer, -1
+	for _, i := range order {
+		if p.Channels[i].DataPointer < end {
+			if last < 0 {
+				return fmt.Errorf("motecldparser: internal error: channel %d data at %d overlaps the channel metadata", i, p.Channels[i].DataPointer)
+			}
+			return fmt.Errorf("motecldparser: internal error: channel %d data at %d overlaps channel %d data", i, p.Channels[i].DataPointer, last)
+		}
+		end, last = p.Channels[i].DataPointer+p.Channels[i].DataSize, i
+	}
+
+	return nil
+}
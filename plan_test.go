@@ -0,0 +1,115 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	f := threeChannelFile()
+	f.AddChannels("not a channel")
+
+	plan, err := f.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := writeBytes(t, f)
+
+	if !reflect.DeepEqual(plan, f.LastWritePlan) {
+		t.Errorf("plan %+v differs from the written layout %+v", plan, f.LastWritePlan)
+	}
+	if plan.Size != int64(len(data)) {
+		t.Errorf("planned size %d, wrote %d bytes", plan.Size, len(data))
+	}
+	if last := plan.Channels[3]; last.DataSize != 0 || last.DataPointer != plan.Size {
+		t.Errorf("non-channel entry planned as %+v", last)
+	}
+}
+
+func TestPlanStream(t *testing.T) {
+	f := twoChannelFile()
+	f.AddChannels(&StreamChannel[float32]{Frequency: 10, Name: "Stream", Reader: strings.NewReader("")})
+	if _, err := f.Plan(); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("got %v, want ErrUnknownSize", err)
+	}
+}
+
+func TestPlanTooManyChannels(t *testing.T) {
+	tooMany := &File{Channels: make([]interface{}, maxChannels+1)}
+	for i := range tooMany.Channels {
+		tooMany.Channels[i] = &Channel[int16]{}
+	}
+	if _, err := tooMany.Plan(); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("%d channels: got %v, want ErrFileTooLarge", len(tooMany.Channels), err)
+	}
+}
+
+func TestCheckDataPointer(t *testing.T) {
+	if err := checkDataPointer(0, math.MaxUint32); err != nil {
+		t.Errorf("last addressable byte: %v", err)
+	}
+	if err := checkDataPointer(0, math.MaxUint32+1); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("past 4 GiB: got %v, want ErrFileTooLarge", err)
+	}
+}
+
+func TestWritePlanCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(p *WritePlan)
+		want    string
+	}{
+		{name: "valid", corrupt: func(*WritePlan) {}},
+		{name: "wrong size", corrupt: func(p *WritePlan) { p.Size++ }, want: "file size"},
+		{name: "gap", corrupt: func(p *WritePlan) { p.Channels[1].DataPointer++ }, want: "channel 1 data pointer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := threeChannelFile().Plan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(plan)
+
+			err = plan.check()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "internal error: "+tt.want) {
+				t.Errorf("got %v, want an internal error about the %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSections(t *testing.T) {
+	f := threeChannelFile()
+	want := writeBytes(t, f)
+
+	metadata, data, err := f.Sections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 || metadata.Offset != 0 || int64(len(metadata.Data)) != f.LastWritePlan.ChannelsDataPointer {
+		t.Fatalf("got metadata of %d bytes and %d data sections", len(metadata.Data), len(data))
+	}
+
+	// Reassemble the file writing the sections in reverse order
+	var out memWriterAt
+	for i := len(data) - 1; i >= 0; i-- {
+		out.WriteAt(data[i].Data, data[i].Offset)
+	}
+	out.WriteAt(metadata.Data, metadata.Offset)
+
+	if !bytes.Equal(out, want) {
+		t.Error("reassembled sections differ from the written file")
+	}
+}
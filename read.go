@@ -0,0 +1,577 @@
+package motecldparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+	"golang.org/x/text/encoding"
+)
+
+var (
+	// ErrInvalidMarker is returned when the file does not start with the LD marker.
+	ErrInvalidMarker = errors.New("motecldparser: invalid LD file marker")
+
+	// ErrUnsupportedDataType is returned when a channel uses a data type that
+	// cannot be decoded.
+	ErrUnsupportedDataType = errors.New("motecldparser: unsupported channel data type")
+
+	// ErrOverlappingData is returned when a channel's data region overlaps the
+	// channel metadata or the data of another channel.
+	ErrOverlappingData = errors.New("motecldparser: overlapping channel data")
+)
+
+// Read parses a MoTeC LD file.
+//
+// The returned File contains the session metadata, event, venue and vehicle
+// information and one channel per channel metadata block. Channels are decoded
+// into *Channel[float32], *Channel[int16] or *Channel[int32] according to their
+// data type. Half-precision float channels (data type 0x07 with 2-byte
+// samples) are widened to *Channel[float32], so writing the file back stores
+// them in single precision. The date and time stored in the header are
+// interpreted as UTC; if they do not match the dd/MM/yyyy and HH:mm:ss
+// formats, Time is left zero and the rest of the file is still read.
+//
+// Data holds the samples exactly as stored: the scaling fields are returned in
+// Shift, Mul, Scale and DecPlaces but not applied (see ScaledValues). Read is
+// therefore the typed counterpart of ReadChannelsAsFloat64; type-switch on the
+// entries of Channels to get the original types.
+//
+// The header is decoded with the fixed layout of ldfile.LdFileHead, while the
+// event, venue, vehicle and channel blocks are located through the pointers
+// stored in the file. No alternate header layout is known, so files from
+// firmware that moves header fields are not detected.
+//
+// The channel ID stored in each metadata block is returned verbatim in the Id
+// field of the channel, whatever its base; it is not used to order or identify
+// channels.
+//
+// Channels are returned in the order of the linked list formed by the channel
+// metadata blocks, starting from the block with no previous channel, which is
+// the order they were added in when the file was written. The blocks may be
+// stored in any order.
+//
+// Both the ACC channel metadata layout and the shorter acti layout are
+// supported. The layout is detected from the distance between consecutive
+// metadata blocks.
+//
+// The data of each channel is read from the location given by its own data
+// pointer, so files whose data blocks are not contiguous or not in the same
+// order as the channel metadata are supported.
+//
+// Only the regions referenced by the header and the channel metadata are
+// read. Bytes beyond the end of the last data region, such as padding or the
+// rest of a container the file is embedded in, are ignored.
+//
+// The number of channels linked from the header must match the channel count
+// it declares; files where they differ are rejected with
+// ErrChannelCountMismatch.
+//
+// Before any channel data is decoded, the data region of every channel is
+// checked against the metadata blocks and the other channels. A file where
+// they overlap is rejected with ErrOverlappingData, and a file where a region
+// extends beyond the end of r with ErrInvalidPointer, so that corrupt or
+// hostile sample counts never cause large allocations.
+//
+// The LD file itself contains no lap or marker information. Beacons, laps and
+// other markers are stored by i2 in the companion .ldx file and are not
+// returned by Read.
+//
+// Example:
+//
+//	fd, err := os.Open("telemetry.ld")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer fd.Close()
+//	file, err := motecldparser.Read(fd)
+func Read(r io.ReaderAt) (*File, error) {
+	return ReadWithOptions(r, ReadOptions{})
+}
+
+// ReadOptions configures how ReadWithOptions decodes a file.
+//
+// The zero value reads the file exactly as stored, like Read.
+type ReadOptions struct {
+	// MissingSentinel, if set, is the value used by the file to mark missing
+	// samples. Samples of float channels equal to it are replaced with NaN.
+	// Integer channels cannot hold NaN and are not modified.
+	MissingSentinel *float32
+
+	// Encoding, if set, is the character set of the strings stored in the
+	// file, such as charmap.ISO8859_1 for older files written with Latin-1
+	// comments. Every string field is converted from it to UTF-8. By default
+	// strings are assumed to be UTF-8 and are returned as stored.
+	Encoding encoding.Encoding
+}
+
+// ReadWithOptions parses a MoTeC LD file like Read, applying the given options
+// to the decoded data.
+//
+// Example:
+//
+//	sentinel := float32(-9999)
+//	file, err := motecldparser.ReadWithOptions(fd, motecldparser.ReadOptions{
+//	    MissingSentinel: &sentinel,
+//	})
+func ReadWithOptions(r io.ReaderAt, opts ReadOptions) (*File, error) {
+	f, l, err := readLayout(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the channel data
+	for i, meta := range l.metas {
+		channel, err := readChannel(r, meta)
+		if err != nil {
+			return nil, fmt.Errorf("read channel %d data: %w", i, err)
+		}
+
+		if c, ok := channel.(*Channel[float32]); ok && opts.MissingSentinel != nil {
+			for j, v := range *c.Data {
+				if v == *opts.MissingSentinel {
+					(*c.Data)[j] = float32(math.NaN())
+				}
+			}
+		}
+
+		f.Channels = append(f.Channels, channel)
+	}
+
+	if opts.Encoding != nil {
+		if err := decodeStrings(f, opts.Encoding.NewDecoder()); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// decodeStrings converts every string field of a file and of its channels to
+// UTF-8.
+func decodeStrings(f *File, decoder *encoding.Decoder) error {
+	fields := []*string{
+		&f.Driver, &f.Vehicle, &f.Venue, &f.ShortComment,
+		&f.EventName, &f.EventSession, &f.EventComment,
+		&f.VehicleId, &f.VehicleType, &f.VehicleComment,
+	}
+
+	for _, channel := range f.Channels {
+		switch c := channel.(type) {
+		case *Channel[float32]:
+			fields = append(fields, &c.Name, &c.ShortName, &c.Unit)
+		case *Channel[int16]:
+			fields = append(fields, &c.Name, &c.ShortName, &c.Unit)
+		case *Channel[int32]:
+			fields = append(fields, &c.Name, &c.ShortName, &c.Unit)
+		}
+	}
+
+	for _, field := range fields {
+		decoded, err := decoder.String(*field)
+		if err != nil {
+			return fmt.Errorf("decode %q: %w", *field, err)
+		}
+		*field = decoded
+	}
+
+	return nil
+}
+
+// layout holds the structural information of a file read by readLayout.
+type layout struct {
+	head         ldfile.LdFileHead
+	metas        []ldfile.LdFileChannelMeta // Channel metadata in file order
+	metaPointers []uint32                   // Offset of each channel metadata block
+	metaSize     uint32                     // Size of a channel metadata block
+}
+
+// readLayout reads the session metadata and the channel metadata of a file,
+// without decoding any channel data.
+//
+// The returned File has no channels. The channel metadata has been checked for
+// overlapping data regions.
+func readLayout(r io.ReaderAt) (*File, *layout, error) {
+	var head ldfile.LdFileHead
+	if err := readAt(r, 0, &head); err != nil {
+		return nil, nil, fmt.Errorf("read header: %w", err)
+	}
+
+	if head.LDMarker != 0x40 {
+		return nil, nil, ErrInvalidMarker
+	}
+
+	f := &File{
+		Driver:       cString(head.Driver[:]),
+		Vehicle:      cString(head.Vehicle[:]),
+		Venue:        cString(head.Venue[:]),
+		ShortComment: cString(head.ShortComment[:]),
+	}
+
+	f.Time, _ = ldfile.ParseHeadTime(head.Date, head.Time)
+
+	// Read the event, venue and vehicle blocks following their pointers
+	if head.EventPointer != 0 {
+		var event ldfile.LdFileEvent
+		if err := readAt(r, int64(head.EventPointer), &event); err != nil {
+			return nil, nil, fmt.Errorf("read event: %w", err)
+		}
+
+		f.EventName = cString(event.Name[:])
+		f.EventSession = cString(event.Session[:])
+		f.EventComment = cString(event.Comment[:])
+
+		if event.VenuePointer != 0 {
+			var venue ldfile.LdFileVenue
+			if err := readAt(r, int64(event.VenuePointer), &venue); err != nil {
+				return nil, nil, fmt.Errorf("read venue: %w", err)
+			}
+
+			if venue.VehiclePointer != 0 {
+				var vehicle ldfile.LdFileVehicle
+				if err := readAt(r, int64(venue.VehiclePointer), &vehicle); err != nil {
+					return nil, nil, fmt.Errorf("read vehicle: %w", err)
+				}
+
+				f.VehicleId = cString(vehicle.Id[:])
+				f.VehicleWeight = vehicle.Weight
+				f.VehicleType = cString(vehicle.Type[:])
+				f.VehicleComment = cString(vehicle.Comment[:])
+			}
+		}
+	}
+
+	metas, metaPointers, err := readChannelList(r, head)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := &layout{
+		head:         head,
+		metas:        metas,
+		metaPointers: metaPointers,
+		metaSize:     detectMetaSize(metas, metaPointers),
+	}
+
+	if err := checkOverlaps(l.metas, l.metaPointers, l.metaSize); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkDataBounds(r, l.metas); err != nil {
+		return nil, nil, err
+	}
+
+	return f, l, nil
+}
+
+// readChannelList reads the channel metadata in linked list order.
+//
+// The list is entered at the header's channel metadata pointer. If that block
+// is not the head of the list, the previous pointers are followed back to the
+// block whose previous pointer is 0, so the channels are returned in the order
+// they were added even if the blocks are stored in a different order. A list
+// that loops back on itself is reported as ErrBrokenChannelList, and a list
+// shorter or longer than the header's channel count as
+// ErrChannelCountMismatch.
+func readChannelList(r io.ReaderAt, head ldfile.LdFileHead) ([]ldfile.LdFileChannelMeta, []uint32, error) {
+	if head.ChannelsCount == 0 {
+		return nil, nil, nil
+	}
+	if head.ChannelsMetaPointer == 0 {
+		return nil, nil, fmt.Errorf("%w: header declares %d channels but no channel metadata", ErrChannelCountMismatch, head.ChannelsCount)
+	}
+
+	// Walk back to the head of the list
+	metaPointer := head.ChannelsMetaPointer
+	visited := map[uint32]bool{metaPointer: true}
+	for {
+		meta, err := readChannelMeta(r, int64(metaPointer))
+		if err != nil {
+			return nil, nil, fmt.Errorf("read channel metadata at %d: %w", metaPointer, err)
+		}
+		if meta.PreviousMetaPointer == 0 {
+			break
+		}
+		if visited[meta.PreviousMetaPointer] {
+			return nil, nil, fmt.Errorf("%w: previous pointers loop back to %d", ErrBrokenChannelList, meta.PreviousMetaPointer)
+		}
+		if uint32(len(visited)) >= head.ChannelsCount {
+			return nil, nil, fmt.Errorf("%w: more than %d channels before %d", ErrBrokenChannelList, head.ChannelsCount, head.ChannelsMetaPointer)
+		}
+
+		metaPointer = meta.PreviousMetaPointer
+		visited[metaPointer] = true
+	}
+
+	// Follow the next pointers. The count comes from the file, so it is not
+	// trusted for preallocation.
+	capacity := min(head.ChannelsCount, 1024)
+	metas := make([]ldfile.LdFileChannelMeta, 0, capacity)
+	metaPointers := make([]uint32, 0, capacity)
+	visited = make(map[uint32]bool, capacity)
+	for i := uint32(0); i < head.ChannelsCount && metaPointer != 0; i++ {
+		if visited[metaPointer] {
+			return nil, nil, fmt.Errorf("%w: channel %d links back to %d", ErrBrokenChannelList, i-1, metaPointer)
+		}
+		visited[metaPointer] = true
+
+		meta, err := readChannelMeta(r, int64(metaPointer))
+		if err != nil {
+			return nil, nil, fmt.Errorf("read channel %d metadata: %w", i, err)
+		}
+
+		metas = append(metas, meta)
+		metaPointers = append(metaPointers, metaPointer)
+		metaPointer = meta.NextMetaPointer
+	}
+
+	if uint32(len(metas)) != head.ChannelsCount {
+		return nil, nil, fmt.Errorf("%w: %d channels linked, header declares %d", ErrChannelCountMismatch, len(metas), head.ChannelsCount)
+	}
+	if metaPointer != 0 {
+		return nil, nil, fmt.Errorf("%w: header declares %d channels, but the last one links to %d", ErrChannelCountMismatch, head.ChannelsCount, metaPointer)
+	}
+
+	return metas, metaPointers, nil
+}
+
+// readChannel decodes the data of a channel into a Channel of the matching type.
+func readChannel(r io.ReaderAt, meta ldfile.LdFileChannelMeta) (AnyChannel, error) {
+	dataType := ldfile.DataType{DataType: meta.DataType, DataTypeLength: meta.DataTypeLength}
+	switch dataType {
+	case ldfile.DataTypeFloat32:
+		return readTypedChannel[float32](r, meta)
+	case ldfile.DataTypeInt16:
+		return readTypedChannel[int16](r, meta)
+	case ldfile.DataTypeInt32:
+		return readTypedChannel[int32](r, meta)
+	case ldfile.DataTypeFloat16:
+		return readHalfChannel(r, meta)
+	default:
+		return nil, fmt.Errorf("%w: 0x%X with %d-byte samples", ErrUnsupportedDataType, meta.DataType, meta.DataTypeLength)
+	}
+}
+
+func readTypedChannel[T float32 | int16 | int32](r io.ReaderAt, meta ldfile.LdFileChannelMeta) (*Channel[T], error) {
+	data := make([]T, meta.DataLength)
+	if err := readAt(r, int64(meta.DataPointer), data); err != nil {
+		return nil, err
+	}
+
+	return &Channel[T]{
+		Frequency: meta.Frequency,
+		Name:      cString(meta.Name[:]),
+		ShortName: cString(meta.ShortName[:]),
+		Unit:      cString(meta.Unit[:]),
+		Data:      &data,
+		Shift:     meta.Shift,
+		Mul:       meta.Mul,
+		Scale:     meta.Scale,
+		DecPlaces: meta.DecPlaces,
+		Id:        meta.ChannelId,
+	}, nil
+}
+
+// readHalfChannel decodes a half-precision float channel into a float32
+// channel.
+func readHalfChannel(r io.ReaderAt, meta ldfile.LdFileChannelMeta) (*Channel[float32], error) {
+	raw := make([]uint16, meta.DataLength)
+	if err := readAt(r, int64(meta.DataPointer), raw); err != nil {
+		return nil, err
+	}
+
+	data := make([]float32, len(raw))
+	for i, bits := range raw {
+		data[i] = float16ToFloat32(bits)
+	}
+
+	return &Channel[float32]{
+		Frequency: meta.Frequency,
+		Name:      cString(meta.Name[:]),
+		ShortName: cString(meta.ShortName[:]),
+		Unit:      cString(meta.Unit[:]),
+		Data:      &data,
+		Shift:     meta.Shift,
+		Mul:       meta.Mul,
+		Scale:     meta.Scale,
+		DecPlaces: meta.DecPlaces,
+		Id:        meta.ChannelId,
+	}, nil
+}
+
+// float16ToFloat32 converts an IEEE 754 half-precision value to float32.
+func float16ToFloat32(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exponent := uint32(bits>>10) & 0x1F
+	mantissa := uint32(bits) & 0x3FF
+
+	switch exponent {
+	case 0:
+		// Zero or subnormal: mantissa * 2^-24
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			value = -value
+		}
+		return value
+	case 0x1F:
+		// Infinity or NaN
+		return math.Float32frombits(sign | 0xFF<<23 | mantissa<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+127-15)<<23 | mantissa<<13)
+	}
+}
+
+// readChannelMeta decodes the channel metadata block at the given offset.
+//
+// Only the bytes shared by the ACC and acti layouts are read, so the block can
+// be decoded without knowing the layout in advance.
+func readChannelMeta(r io.ReaderAt, offset int64) (ldfile.LdFileChannelMeta, error) {
+	var meta ldfile.LdFileChannelMeta
+
+	buf := make([]byte, ldfile.ChannelMetaSize)
+	if _, err := r.ReadAt(buf[:ldfile.ChannelMetaSizeActi], offset); err != nil {
+		return meta, err
+	}
+
+	err := binary.Read(bytes.NewReader(buf), binary.LittleEndian, &meta)
+	return meta, err
+}
+
+// detectMetaSize returns the size of the channel metadata blocks, telling the
+// acti layout apart from the ACC one.
+//
+// The size is inferred from the smallest distance between two metadata blocks
+// or, for single-channel files, between the metadata block and its data. The
+// ACC size is assumed when neither matches the acti layout.
+func detectMetaSize(metas []ldfile.LdFileChannelMeta, metaPointers []uint32) uint32 {
+	var stride uint32
+	switch {
+	case len(metas) >= 2:
+		sorted := slices.Clone(metaPointers)
+		slices.Sort(sorted)
+		stride = sorted[1] - sorted[0]
+		for i := 2; i < len(sorted); i++ {
+			stride = min(stride, sorted[i]-sorted[i-1])
+		}
+	case len(metas) == 1:
+		stride = metas[0].DataPointer - metaPointers[0]
+	}
+
+	if stride == ldfile.ChannelMetaSizeActi {
+		return ldfile.ChannelMetaSizeActi
+	}
+	return ldfile.ChannelMetaSize
+}
+
+// checkOverlaps verifies that no channel data region overlaps a channel
+// metadata block or the data region of another channel.
+func checkOverlaps(metas []ldfile.LdFileChannelMeta, metaPointers []uint32, metaSize uint32) error {
+	type region struct {
+		start, end uint64
+		name       string
+	}
+
+	regions := make([]region, 0, 2*len(metas))
+	for i, meta := range metas {
+		regions = append(regions, region{
+			start: uint64(metaPointers[i]),
+			end:   uint64(metaPointers[i]) + uint64(metaSize),
+			name:  fmt.Sprintf("channel %d metadata", i),
+		})
+
+		length := uint64(meta.DataLength) * uint64(meta.DataTypeLength)
+		if length > 0 {
+			regions = append(regions, region{
+				start: uint64(meta.DataPointer),
+				end:   uint64(meta.DataPointer) + length,
+				name:  fmt.Sprintf("channel %d data", i),
+			})
+		}
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].start < regions[j].start
+	})
+
+	// Compare each region with the one reaching furthest among the previous ones
+	last := 0
+	for i := 1; i < len(regions); i++ {
+		if regions[i].start < regions[last].end {
+			return fmt.Errorf("%w: %s overlaps %s", ErrOverlappingData, regions[last].name, regions[i].name)
+		}
+		if regions[i].end > regions[last].end {
+			last = i
+		}
+	}
+
+	return nil
+}
+
+// checkDataBounds verifies that the data region of every channel ends within
+// r, so that sample buffers sized from the untrusted DataLength field are
+// never allocated for data the source does not hold.
+//
+// The size of r is used when it is known (see sourceSize); otherwise the last
+// byte of each region is read.
+func checkDataBounds(r io.ReaderAt, metas []ldfile.LdFileChannelMeta) error {
+	size, known := sourceSize(r)
+	for i, meta := range metas {
+		length := uint64(meta.DataLength) * uint64(meta.DataTypeLength)
+		if length == 0 {
+			continue
+		}
+
+		end := uint64(meta.DataPointer) + length
+		if known {
+			if end > uint64(size) {
+				return fmt.Errorf("%w: channel %d data at %d, length %d, file size %d", ErrInvalidPointer, i, meta.DataPointer, length, size)
+			}
+			continue
+		}
+
+		var last [1]byte
+		if n, err := r.ReadAt(last[:], int64(end-1)); n < 1 {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("%w: channel %d data at %d, length %d, beyond the end of the file", ErrInvalidPointer, i, meta.DataPointer, length)
+			}
+			return fmt.Errorf("read channel %d data: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// sourceSize returns the size of r if it can be known without reading, as for
+// a bytes.Reader, an io.SectionReader or a regular *os.File.
+func sourceSize(r io.ReaderAt) (int64, bool) {
+	switch s := r.(type) {
+	case interface{ Size() int64 }:
+		return s.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := s.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size(), true
+		}
+	}
+	return 0, false
+}
+
+// readAt decodes little-endian data from the given offset.
+func readAt(r io.ReaderAt, offset int64, data any) error {
+	section := io.NewSectionReader(r, offset, int64(binary.Size(data)))
+	return binary.Read(section, binary.LittleEndian, data)
+}
+
+// cString returns the string stored in a null-padded byte array.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,445 @@
+package motecldparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// writeBytes writes f to memory and returns the bytes of the LD file.
+func writeBytes(t testing.TB, f *File) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// roundTrip writes f and reads it back with Read.
+func roundTrip(t testing.TB, f *File) *File {
+	t.Helper()
+
+	read, err := Read(bytes.NewReader(writeBytes(t, f)))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return read
+}
+
+// twoChannelFile returns a file with a float32 and an int16 channel.
+func twoChannelFile() *File {
+	f := &File{Driver: "Driver", Venue: "Venue"}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", Unit: "km/h", Data: &[]float32{1, 2, 3, 4}},
+		&Channel[int16]{Frequency: 5, Name: "Gear", Data: &[]int16{1, 2}},
+	)
+	return f
+}
+
+// patchMeta overwrites a uint32 field of the metadata block of a channel of a
+// file written from f. offset is the offset of the field in the block.
+func patchMeta(t testing.TB, f *File, data []byte, channel int, offset int64, value uint32) {
+	t.Helper()
+
+	pointer := f.LastWritePlan.Channels[channel].MetaPointer + offset
+	binary.LittleEndian.PutUint32(data[pointer:], value)
+}
+
+// Offsets of fields of ldfile.LdFileChannelMeta.
+const (
+	metaDataPointerOffset = 8
+	metaDataLengthOffset  = 12
+)
+
+// sizelessReader hides the Size method of a reader, forcing the readers of
+// the package to probe the end of the data.
+type sizelessReader struct {
+	r io.ReaderAt
+}
+
+func (s sizelessReader) ReadAt(p []byte, off int64) (int, error) {
+	return s.r.ReadAt(p, off)
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	read := roundTrip(t, twoChannelFile())
+
+	if read.Driver != "Driver" || read.Venue != "Venue" {
+		t.Errorf("metadata = %q, %q", read.Driver, read.Venue)
+	}
+	if len(read.Channels) != 2 {
+		t.Fatalf("got %d channels, want 2", len(read.Channels))
+	}
+
+	speed, ok := read.Channels[0].(*Channel[float32])
+	if !ok {
+		t.Fatalf("channel 0 is %T, want *Channel[float32]", read.Channels[0])
+	}
+	if speed.Name != "Speed" || speed.Unit != "km/h" || speed.Frequency != 10 {
+		t.Errorf("channel 0 = %q %q %d Hz", speed.Name, speed.Unit, speed.Frequency)
+	}
+	if !slices.Equal(*speed.Data, []float32{1, 2, 3, 4}) {
+		t.Errorf("channel 0 data = %v", *speed.Data)
+	}
+
+	gear, ok := read.Channels[1].(*Channel[int16])
+	if !ok {
+		t.Fatalf("channel 1 is %T, want *Channel[int16]", read.Channels[1])
+	}
+	if !slices.Equal(*gear.Data, []int16{1, 2}) {
+		t.Errorf("channel 1 data = %v", *gear.Data)
+	}
+}
+
+func TestReadRejectsCorruptData(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel int
+		offset  int64
+		value   func(f *File) uint32
+		want    error
+	}{
+		{
+			name:    "data length beyond the file",
+			channel: 1,
+			offset:  metaDataLengthOffset,
+			value:   func(*File) uint32 { return 0x20000000 },
+			want:    ErrInvalidPointer,
+		},
+		{
+			name:    "largest data length",
+			channel: 1,
+			offset:  metaDataLengthOffset,
+			value:   func(*File) uint32 { return 0xFFFFFFFF },
+			want:    ErrInvalidPointer,
+		},
+		{
+			name:    "data pointer beyond the file",
+			channel: 0,
+			offset:  metaDataPointerOffset,
+			value:   func(*File) uint32 { return 0x7FFFFFFF },
+			want:    ErrInvalidPointer,
+		},
+		{
+			name:    "data shared by two channels",
+			channel: 1,
+			offset:  metaDataPointerOffset,
+			value:   func(f *File) uint32 { return uint32(f.LastWritePlan.Channels[0].DataPointer) },
+			want:    ErrOverlappingData,
+		},
+		{
+			name:    "data inside the metadata",
+			channel: 0,
+			offset:  metaDataPointerOffset,
+			value:   func(f *File) uint32 { return uint32(f.LastWritePlan.Channels[1].MetaPointer) },
+			want:    ErrOverlappingData,
+		},
+		{
+			name:    "data overlapping the next channel",
+			channel: 0,
+			offset:  metaDataLengthOffset,
+			value:   func(*File) uint32 { return 5 },
+			want:    ErrOverlappingData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := twoChannelFile()
+			data := writeBytes(t, f)
+			patchMeta(t, f, data, tt.channel, tt.offset, tt.value(f))
+
+			readers := map[string]func() error{
+				"Read": func() error {
+					_, err := Read(bytes.NewReader(data))
+					return err
+				},
+				"Read without size": func() error {
+					_, err := Read(sizelessReader{bytes.NewReader(data)})
+					return err
+				},
+				"ReadContext": func() error {
+					_, err := ReadContext(context.Background(), bytes.NewReader(data))
+					return err
+				},
+				"OpenLazy": func() error {
+					_, err := OpenLazy(bytes.NewReader(data))
+					return err
+				},
+				"ReadChannelsAsFloat64": func() error {
+					_, _, err := ReadChannelsAsFloat64(bytes.NewReader(data))
+					return err
+				},
+				"ReadChannelsAsFloat32": func() error {
+					_, err := ReadChannelsAsFloat32(bytes.NewReader(data))
+					return err
+				},
+			}
+
+			for name, read := range readers {
+				if err := read(); !errors.Is(err, tt.want) {
+					t.Errorf("%s: got %v, want %v", name, err, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadInvalidMarker(t *testing.T) {
+	data := writeBytes(t, twoChannelFile())
+	data[0] = 0
+
+	if _, err := Read(bytes.NewReader(data)); !errors.Is(err, ErrInvalidMarker) {
+		t.Errorf("got %v, want ErrInvalidMarker", err)
+	}
+}
+
+// relayout writes f and stores the channel metadata blocks and data regions
+// in the given orders, rewriting every pointer so the file stays valid.
+// metaSize selects the metadata layout, ldfile.ChannelMetaSize for ACC or
+// ldfile.ChannelMetaSizeActi for acti. The header points to the block stored
+// first, which is not necessarily the head of the list.
+func relayout(t testing.TB, f *File, metaSize int64, metaOrder, dataOrder []int) []byte {
+	t.Helper()
+
+	data := writeBytes(t, f)
+	plan := f.LastWritePlan
+	n := len(plan.Channels)
+
+	metaPointers := make([]int64, n)
+	for slot, i := range metaOrder {
+		metaPointers[i] = plan.ChannelsMetaPointer + metaSize*int64(slot)
+	}
+	dataPointers := make([]int64, n)
+	pointer := plan.ChannelsMetaPointer + metaSize*int64(n)
+	for _, i := range dataOrder {
+		dataPointers[i] = pointer
+		pointer += plan.Channels[i].DataSize
+	}
+
+	out := make([]byte, pointer)
+	copy(out, data[:plan.ChannelsMetaPointer])
+	binary.LittleEndian.PutUint32(out[8:], uint32(metaPointers[metaOrder[0]]))
+	binary.LittleEndian.PutUint32(out[12:], uint32(plan.ChannelsMetaPointer+metaSize*int64(n)))
+
+	for i, channel := range plan.Channels {
+		meta := out[metaPointers[i] : metaPointers[i]+metaSize]
+		copy(meta, data[channel.MetaPointer:])
+
+		var previous, next uint32
+		if i > 0 {
+			previous = uint32(metaPointers[i-1])
+		}
+		if i < n-1 {
+			next = uint32(metaPointers[i+1])
+		}
+		binary.LittleEndian.PutUint32(meta[0:], previous)
+		binary.LittleEndian.PutUint32(meta[4:], next)
+		binary.LittleEndian.PutUint32(meta[metaDataPointerOffset:], uint32(dataPointers[i]))
+
+		copy(out[dataPointers[i]:], data[channel.DataPointer:channel.DataPointer+channel.DataSize])
+	}
+
+	return out
+}
+
+// threeChannelFile returns a file with a channel of every writable type.
+func threeChannelFile() *File {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{1.5, 2.5, 3.5}},
+		&Channel[int16]{Frequency: 5, Name: "Gear", Data: &[]int16{1, 2}},
+		&Channel[int32]{Frequency: 1, Name: "Lap", Data: &[]int32{7}},
+	)
+	return f
+}
+
+// checkThreeChannels verifies that f holds the channels of threeChannelFile,
+// in order.
+func checkThreeChannels(t *testing.T, f *File) {
+	t.Helper()
+
+	if len(f.Channels) != 3 {
+		t.Fatalf("got %d channels, want 3", len(f.Channels))
+	}
+	speed, ok1 := f.Channels[0].(*Channel[float32])
+	gear, ok2 := f.Channels[1].(*Channel[int16])
+	lap, ok3 := f.Channels[2].(*Channel[int32])
+	if !ok1 || !ok2 || !ok3 {
+		t.Fatalf("channels are %T, %T, %T", f.Channels[0], f.Channels[1], f.Channels[2])
+	}
+	if speed.Name != "Speed" || !slices.Equal(*speed.Data, []float32{1.5, 2.5, 3.5}) {
+		t.Errorf("channel 0 = %q %v", speed.Name, *speed.Data)
+	}
+	if gear.Name != "Gear" || !slices.Equal(*gear.Data, []int16{1, 2}) {
+		t.Errorf("channel 1 = %q %v", gear.Name, *gear.Data)
+	}
+	if lap.Name != "Lap" || !slices.Equal(*lap.Data, []int32{7}) {
+		t.Errorf("channel 2 = %q %v", lap.Name, *lap.Data)
+	}
+}
+
+// relayoutTest describes a layout produced by relayout.
+type relayoutTest struct {
+	name      string
+	metaOrder []int
+	dataOrder []int
+}
+
+// readRelayout reads three-channel files laid out in every given order.
+func readRelayout(t *testing.T, tests []relayoutTest) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := relayout(t, threeChannelFile(), ldfile.ChannelMetaSize, tt.metaOrder, tt.dataOrder)
+
+			read, err := Read(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkThreeChannels(t, read)
+		})
+	}
+}
+
+func TestReadDataOutOfOrder(t *testing.T) {
+	readRelayout(t, []relayoutTest{
+		{name: "in order", metaOrder: []int{0, 1, 2}, dataOrder: []int{0, 1, 2}},
+		{name: "reversed", metaOrder: []int{0, 1, 2}, dataOrder: []int{2, 1, 0}},
+		{name: "shuffled", metaOrder: []int{0, 1, 2}, dataOrder: []int{1, 2, 0}},
+	})
+}
+
+func TestReadMetadataOutOfOrder(t *testing.T) {
+	readRelayout(t, []relayoutTest{
+		{name: "reversed", metaOrder: []int{2, 1, 0}, dataOrder: []int{0, 1, 2}},
+		{name: "header points mid-list", metaOrder: []int{1, 0, 2}, dataOrder: []int{0, 1, 2}},
+		{name: "data shuffled too", metaOrder: []int{2, 0, 1}, dataOrder: []int{1, 0, 2}},
+	})
+}
+
+func TestReadChannelCountMismatch(t *testing.T) {
+	const channelsCountOffset = 86
+
+	for _, count := range []uint32{1, 3, 1000} {
+		data := writeBytes(t, twoChannelFile())
+		binary.LittleEndian.PutUint32(data[channelsCountOffset:], count)
+
+		if _, err := Read(bytes.NewReader(data)); !errors.Is(err, ErrChannelCountMismatch) {
+			t.Errorf("count %d: got %v, want ErrChannelCountMismatch", count, err)
+		}
+		if _, err := OpenLazy(bytes.NewReader(data)); !errors.Is(err, ErrChannelCountMismatch) {
+			t.Errorf("count %d: OpenLazy got %v, want ErrChannelCountMismatch", count, err)
+		}
+	}
+}
+
+func TestReadMissingSentinel(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{1, -9999, 3}},
+		&Channel[int16]{Frequency: 10, Name: "Gear", Data: &[]int16{-9999}},
+	)
+
+	sentinel := float32(-9999)
+	read, err := ReadWithOptions(bytes.NewReader(writeBytes(t, f)), ReadOptions{MissingSentinel: &sentinel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := *read.Channels[0].(*Channel[float32]).Data; d[0] != 1 || !math.IsNaN(float64(d[1])) || d[2] != 3 {
+		t.Errorf("speed = %v, want the sentinel replaced with NaN", d)
+	}
+	if gear := *read.Channels[1].(*Channel[int16]).Data; gear[0] != -9999 {
+		t.Errorf("integer channel modified: %v", gear)
+	}
+}
+
+func TestReadEncoding(t *testing.T) {
+	f := &File{Driver: "Jos\xe9"} // Latin-1 "José"
+	f.AddChannels(&Channel[float32]{Frequency: 10, Name: "Temp", Unit: "\xb0C", Data: &[]float32{1}})
+	data := writeBytes(t, f)
+
+	read, err := ReadWithOptions(bytes.NewReader(data), ReadOptions{Encoding: charmap.ISO8859_1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit := read.Channels[0].(*Channel[float32]).Unit; read.Driver != "José" || unit != "°C" {
+		t.Errorf("driver %q, unit %q", read.Driver, unit)
+	}
+
+	// Without an encoding the bytes are kept as they are
+	read, err = Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Driver != "Jos\xe9" {
+		t.Errorf("raw driver = %q", read.Driver)
+	}
+}
+
+func TestReadFloat16(t *testing.T) {
+	const metaDataTypeOffset = 18
+
+	// Store half-precision samples in an int16 channel and mark it as float16
+	f := &File{}
+	f.AddChannels(&Channel[int16]{Frequency: 10, Name: "Half", Data: &[]int16{0x3C00, -0x4000, 0x3800}})
+	data := writeBytes(t, f)
+	pointer := f.LastWritePlan.Channels[0].MetaPointer + metaDataTypeOffset
+	binary.LittleEndian.PutUint16(data[pointer:], ldfile.DataTypeFloat16.DataType)
+
+	read, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	half, ok := read.Channels[0].(*Channel[float32])
+	if !ok {
+		t.Fatalf("got %T, want *Channel[float32]", read.Channels[0])
+	}
+	if want := []float32{1, -2, 0.5}; !slices.Equal(*half.Data, want) {
+		t.Errorf("got %v, want %v", *half.Data, want)
+	}
+
+	buf := make([]float64, 3)
+	lf, err := OpenLazy(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lf.ChannelInto("Half", buf); err != nil || !slices.Equal(buf, []float64{1, -2, 0.5}) {
+		t.Errorf("lazy: got %v, %v", buf, err)
+	}
+}
+
+func TestFloat16ToFloat32(t *testing.T) {
+	tests := []struct {
+		bits uint16
+		want float32
+	}{
+		{0x0000, 0},
+		{0x3C00, 1},
+		{0xC000, -2},
+		{0x7BFF, 65504},
+		{0x0001, 1.0 / (1 << 24)},
+		{0x8001, -1.0 / (1 << 24)},
+		{0x7C00, float32(math.Inf(1))},
+		{0xFC00, float32(math.Inf(-1))},
+	}
+
+	for _, tt := range tests {
+		if got := float16ToFloat32(tt.bits); got != tt.want {
+			t.Errorf("0x%04X: got %v, want %v", tt.bits, got, tt.want)
+		}
+	}
+	if got := float16ToFloat32(0x7E00); !math.IsNaN(float64(got)) {
+		t.Errorf("0x7E00: got %v, want NaN", got)
+	}
+}
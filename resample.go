@@ -0,0 +1,211 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Resample returns a copy of the channel sampled at targetHz.
+//
+// The new samples are computed by linear interpolation between the nearest
+// original samples; for integer channels the interpolated values are rounded
+// to the nearest integer. The duration of the channel is preserved, so the
+// result holds len(Data) * targetHz / Frequency samples, rounded to the
+// nearest integer. The original channel is not modified.
+//
+// A channel with a zero Frequency or a zero targetHz cannot be resampled and
+// is returned as an unmodified copy.
+func (c *Channel[T]) Resample(targetHz uint16) *Channel[T] {
+	resampled := *c
+
+	n := c.SampleCount()
+	if c.Frequency == 0 || targetHz == 0 || n == 0 || c.Frequency == targetHz {
+		data := make([]T, n)
+		if n > 0 {
+			copy(data, *c.Data)
+		}
+		resampled.Data = &data
+		return &resampled
+	}
+
+	ratio := float64(c.Frequency) / float64(targetHz)
+	data := make([]T, int(math.Round(float64(n)/ratio)))
+	for i := range data {
+		position := float64(i) * ratio
+		left := int(position)
+		if left >= n-1 {
+			data[i] = (*c.Data)[n-1]
+			continue
+		}
+
+		weight := position - float64(left)
+		value := float64((*c.Data)[left])*(1-weight) + float64((*c.Data)[left+1])*weight
+		data[i] = fromFloat64[T](value)
+	}
+
+	resampled.Frequency = targetHz
+	resampled.Data = &data
+	return &resampled
+}
+
+// DownsampleAverage returns a copy of an int16 channel with every factor
+// consecutive samples replaced by their mean, rounded to the nearest integer.
+//
+// Averaging reduces the noise of integer sensors better than keeping one
+// sample out of factor. A trailing block shorter than factor is averaged over
+// its own length. The frequency of the result is Frequency / factor, so factor
+// must divide Frequency exactly: otherwise the duration of the channel would
+// drift, or its frequency drop to zero, and ErrInvalidFrequency is returned. A
+// factor below 2 returns an unmodified copy. The original channel is not
+// modified.
+//
+// Example:
+//
+//	smoothed, err := motecldparser.DownsampleAverage(rawPressure, 10) // 1 kHz to 100 Hz
+func DownsampleAverage(c *Channel[int16], factor int) (*Channel[int16], error) {
+	downsampled := *c
+
+	n := c.SampleCount()
+	if factor < 2 {
+		data := make([]int16, n)
+		if n > 0 {
+			copy(data, *c.Data)
+		}
+		downsampled.Data = &data
+		return &downsampled, nil
+	}
+
+	if factor > int(c.Frequency) || int(c.Frequency)%factor != 0 {
+		return nil, fmt.Errorf("%w: factor %d does not divide %d Hz", ErrInvalidFrequency, factor, c.Frequency)
+	}
+
+	data := make([]int16, (n+factor-1)/factor)
+	for i := range data {
+		block := (*c.Data)[i*factor : min((i+1)*factor, n)]
+
+		sum := 0
+		for _, v := range block {
+			sum += int(v)
+		}
+		data[i] = int16(math.Round(float64(sum) / float64(len(block))))
+	}
+
+	downsampled.Frequency = c.Frequency / uint16(factor)
+	downsampled.Data = &data
+	return &downsampled, nil
+}
+
+// Normalize resamples every channel of the file to targetHz.
+//
+// The file is modified in place: each channel in File.Channels is replaced by
+// the result of its Resample method, so channels with the same duration end up
+// with the same number of samples. Float channels are linearly interpolated
+// and integer channels are interpolated and rounded. Stream channels, whose
+// data is not available until the file is written, are left untouched.
+func (f *File) Normalize(targetHz uint16) {
+	for i, channel := range f.Channels {
+		switch c := channel.(type) {
+		case *Channel[float32]:
+			f.Channels[i] = c.Resample(targetHz)
+		case *Channel[int16]:
+			f.Channels[i] = c.Resample(targetHz)
+		case *Channel[int32]:
+			f.Channels[i] = c.Resample(targetHz)
+		}
+	}
+}
+
+// fromFloat64 converts a value to the sample type T, rounding and clamping it
+// for integer types.
+func fromFloat64[T float32 | int16 | int32](v float64) T {
+	var zero T
+	switch any(zero).(type) {
+	case int16:
+		return T(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+	case int32:
+		return T(math.Max(math.MinInt32, math.Min(math.MaxInt32, math.Round(v))))
+	default:
+		return T(v)
+	}
+}
+
+// ErrLengthMismatch is returned when channels that must have the same number
+// of samples do not.
+var ErrLengthMismatch = errors.New("motecldparser: channel lengths differ")
+
+// MergeInterleaved combines two channels measuring the same quantity into one
+// channel at twice their frequency.
+//
+// The samples are interleaved as a[0], b[0], a[1], b[1], ... This assumes that
+// b is sampled half a period after a, e.g. two sensors read in alternation or
+// a single sensor read by two loggers in antiphase; with any other phase the
+// merged channel is distorted. Both channels must have the same Frequency and
+// the same number of samples, otherwise ErrInvalidFrequency or
+// ErrLengthMismatch is returned.
+//
+// The result takes Name, ShortName, Unit and scaling from a. The original
+// channels are not modified.
+func MergeInterleaved(a, b *Channel[float32]) (*Channel[float32], error) {
+	if a.Frequency != b.Frequency || a.Frequency == 0 || a.Frequency > math.MaxUint16/2 {
+		return nil, fmt.Errorf("%w: cannot interleave %d Hz and %d Hz", ErrInvalidFrequency, a.Frequency, b.Frequency)
+	}
+
+	n := a.SampleCount()
+	if b.SampleCount() != n {
+		return nil, fmt.Errorf("%w: %d and %d samples", ErrLengthMismatch, n, b.SampleCount())
+	}
+
+	data := make([]float32, 2*n)
+	for i := 0; i < n; i++ {
+		data[2*i] = (*a.Data)[i]
+		data[2*i+1] = (*b.Data)[i]
+	}
+
+	merged := *a
+	merged.Frequency = 2 * a.Frequency
+	merged.Data = &data
+	return &merged, nil
+}
+
+// Preview returns a copy of the file with every channel resampled to at most
+// maxSamplesPerChannel samples, e.g. for a lightweight preview in a web
+// viewer.
+//
+// Channels longer than the cap are resampled with Resample to the highest
+// whole frequency that fits, which preserves their shape and duration; shorter
+// channels are copied unchanged. As frequencies cannot go below 1 Hz, a
+// channel lasting more than maxSamplesPerChannel seconds is resampled to 1 Hz
+// and still exceeds the cap. Stream channels, whose data is not held in
+// memory, are left out. The original file is not modified.
+//
+// Example:
+//
+//	preview := file.Preview(5000)
+func (f *File) Preview(maxSamplesPerChannel int) *File {
+	preview := *f
+	preview.Channels = make([]interface{}, 0, len(f.Channels))
+	preview.LastWritePlan = nil
+
+	for _, channel := range f.Channels {
+		switch c := channel.(type) {
+		case *Channel[float32]:
+			preview.Channels = append(preview.Channels, c.Resample(previewFrequency(c.Frequency, c.SampleCount(), maxSamplesPerChannel)))
+		case *Channel[int16]:
+			preview.Channels = append(preview.Channels, c.Resample(previewFrequency(c.Frequency, c.SampleCount(), maxSamplesPerChannel)))
+		case *Channel[int32]:
+			preview.Channels = append(preview.Channels, c.Resample(previewFrequency(c.Frequency, c.SampleCount(), maxSamplesPerChannel)))
+		}
+	}
+
+	return &preview
+}
+
+// previewFrequency returns the highest frequency at which n samples at freq
+// are resampled to at most limit samples.
+func previewFrequency(freq uint16, n, limit int) uint16 {
+	if n <= limit || freq == 0 {
+		return freq
+	}
+	return uint16(max(1, int64(freq)*int64(max(limit, 0))/int64(n)))
+}
@@ -0,0 +1,135 @@
+package motecldparser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDownsampleAverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		freq     uint16
+		data     []int16
+		factor   int
+		wantFreq uint16
+		wantData []int16
+		wantErr  error
+	}{
+		{name: "exact blocks", freq: 100, data: []int16{1, 3, 5, 7}, factor: 2, wantFreq: 50, wantData: []int16{2, 6}},
+		{name: "trailing block", freq: 100, data: []int16{1, 2, 3, 10, 20}, factor: 4, wantFreq: 25, wantData: []int16{4, 20}},
+		{name: "rounding", freq: 10, data: []int16{1, 2}, factor: 2, wantFreq: 5, wantData: []int16{2}},
+		{name: "factor below 2", freq: 10, data: []int16{1, 2}, factor: 1, wantFreq: 10, wantData: []int16{1, 2}},
+		{name: "factor above frequency", freq: 10, data: []int16{1, 2}, factor: 20, wantErr: ErrInvalidFrequency},
+		{name: "factor not dividing frequency", freq: 100, data: []int16{1, 2, 3}, factor: 3, wantErr: ErrInvalidFrequency},
+		{name: "zero frequency", freq: 0, data: []int16{1, 2}, factor: 2, wantErr: ErrInvalidFrequency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Channel[int16]{Frequency: tt.freq, Name: "Pressure", Data: &tt.data}
+
+			got, err := DownsampleAverage(c, tt.factor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Frequency != tt.wantFreq || !slices.Equal(*got.Data, tt.wantData) {
+				t.Errorf("got %d Hz %v, want %d Hz %v", got.Frequency, *got.Data, tt.wantFreq, tt.wantData)
+			}
+			if got.Name != "Pressure" {
+				t.Errorf("name = %q", got.Name)
+			}
+		})
+	}
+}
+
+func TestResample(t *testing.T) {
+	c := &Channel[float32]{Frequency: 10, Data: &[]float32{0, 10, 20, 30}}
+
+	up := c.Resample(20)
+	if up.Frequency != 20 || !slices.Equal(*up.Data, []float32{0, 5, 10, 15, 20, 25, 30, 30}) {
+		t.Errorf("upsampled to %d Hz: %v", up.Frequency, *up.Data)
+	}
+
+	down := c.Resample(5)
+	if down.Frequency != 5 || !slices.Equal(*down.Data, []float32{0, 20}) {
+		t.Errorf("downsampled to %d Hz: %v", down.Frequency, *down.Data)
+	}
+
+	if !slices.Equal(*c.Data, []float32{0, 10, 20, 30}) {
+		t.Errorf("original modified: %v", *c.Data)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	stream := &StreamChannel[float32]{Frequency: 3, Name: "Stream"}
+	f := twoChannelFile()
+	f.AddChannels(stream)
+
+	f.Normalize(10)
+
+	if speed := f.Channels[0].(*Channel[float32]); speed.Frequency != 10 || !slices.Equal(*speed.Data, []float32{1, 2, 3, 4}) {
+		t.Errorf("speed = %d Hz %v", speed.Frequency, *speed.Data)
+	}
+	if gear := f.Channels[1].(*Channel[int16]); gear.Frequency != 10 || !slices.Equal(*gear.Data, []int16{1, 2, 2, 2}) {
+		t.Errorf("gear = %d Hz %v", gear.Frequency, *gear.Data)
+	}
+	if f.Channels[2] != stream || stream.Frequency != 3 {
+		t.Error("stream channel modified")
+	}
+}
+
+func TestMergeInterleaved(t *testing.T) {
+	a := &Channel[float32]{Frequency: 10, Name: "A", Unit: "bar", Data: &[]float32{1, 3}}
+	b := &Channel[float32]{Frequency: 10, Name: "B", Data: &[]float32{2, 4}}
+
+	merged, err := MergeInterleaved(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Frequency != 20 || merged.Name != "A" || merged.Unit != "bar" || !slices.Equal(*merged.Data, []float32{1, 2, 3, 4}) {
+		t.Errorf("got %+v %v", merged, *merged.Data)
+	}
+
+	tests := []struct {
+		name string
+		b    *Channel[float32]
+		want error
+	}{
+		{name: "different frequency", b: &Channel[float32]{Frequency: 5, Data: &[]float32{2, 4}}, want: ErrInvalidFrequency},
+		{name: "different length", b: &Channel[float32]{Frequency: 10, Data: &[]float32{2}}, want: ErrLengthMismatch},
+	}
+	for _, tt := range tests {
+		if _, err := MergeInterleaved(a, tt.b); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	long := make([]float32, 1000)
+	f := &File{Driver: "Driver"}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 100, Name: "Long", Data: &long},
+		&Channel[int16]{Frequency: 10, Name: "Short", Data: &[]int16{1, 2}},
+		&StreamChannel[int32]{Frequency: 10, Name: "Stream"},
+	)
+
+	preview := f.Preview(250)
+	if preview.Driver != "Driver" || len(preview.Channels) != 2 {
+		t.Fatalf("got driver %q and %d channels", preview.Driver, len(preview.Channels))
+	}
+	if c := preview.Channels[0].(*Channel[float32]); c.Frequency != 25 || len(*c.Data) != 250 {
+		t.Errorf("long channel at %d Hz with %d samples", c.Frequency, len(*c.Data))
+	}
+	if c := preview.Channels[1].(*Channel[int16]); c.Frequency != 10 || !slices.Equal(*c.Data, []int16{1, 2}) {
+		t.Errorf("short channel = %d Hz %v", c.Frequency, *c.Data)
+	}
+	if len(f.Channels) != 3 || f.Channels[0].(*Channel[float32]).Frequency != 100 {
+		t.Error("original file modified")
+	}
+}
@@ -0,0 +1,316 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrRangeNotRepresentable is returned when the values of a channel cannot
+	// be represented with the scaling fields of the LD format.
+	ErrRangeNotRepresentable = errors.New("motecldparser: value range cannot be represented")
+
+	// ErrInvalidDecPlaces is returned when a number of decimal places is
+	// outside [0, MaxDecPlaces].
+	ErrInvalidDecPlaces = errors.New("motecldparser: invalid number of decimal places")
+)
+
+// MaxDecPlaces is the largest number of decimal places set by this package.
+// Beyond it the factor 10^DecPlaces exceeds the precision of float32 samples.
+const MaxDecPlaces = 9
+
+// ScaledValues returns the physical values of the channel samples.
+//
+// Each stored sample is converted with the channel's scaling fields:
+//
+//	value = (raw / Scale * 10^-DecPlaces + Shift) * Mul
+//
+// where a zero Mul or Scale is treated as 1. This matches how i2 displays the
+// channel. Returns nil if the channel has no data.
+func (c *Channel[T]) ScaledValues() []float64 {
+	if c.Data == nil {
+		return nil
+	}
+
+	shift, factor := scaling(c.Shift, c.Mul, c.Scale, c.DecPlaces)
+	values := make([]float64, len(*c.Data))
+	for i, v := range *c.Data {
+		values[i] = float64(v)*factor + shift
+	}
+	return values
+}
+
+// QuantizeToInt16 converts a float32 channel to an int16 channel, choosing
+// the scaling fields that give the finest resolution for the data.
+//
+// The offset is centered on the observed range of the data and Scale and
+// DecPlaces are chosen so that the largest deviation from it still fits an
+// int16. Mul is only raised above 1 for ranges too wide for an int16 at unit
+// resolution. ScaledValues of the result reconstructs the original samples
+// within the quantization step, which is Mul / (Scale * 10^DecPlaces). Name,
+// ShortName, Unit and Frequency are preserved and the original channel is not
+// modified.
+//
+// Returns ErrNonFiniteValue if the channel holds NaN or infinite samples, and
+// ErrRangeNotRepresentable if the range is too wide for the scaling fields.
+//
+// Example:
+//
+//	pressure, err := motecldparser.QuantizeToInt16(rawPressure)
+func QuantizeToInt16(c *Channel[float32]) (*Channel[int16], error) {
+	quantized := &Channel[int16]{
+		Frequency: c.Frequency,
+		Name:      c.Name,
+		ShortName: c.ShortName,
+		Unit:      c.Unit,
+	}
+
+	n := c.SampleCount()
+	data := make([]int16, n)
+	quantized.Data = &data
+	if n == 0 {
+		return quantized, nil
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, v := range *c.Data {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil, fmt.Errorf("%w: sample %d is %v", ErrNonFiniteValue, i, v)
+		}
+		lo = math.Min(lo, float64(v))
+		hi = math.Max(hi, float64(v))
+	}
+
+	// Find the smallest multiplier whose offset leaves a deviation that fits
+	// an int16 at unit resolution
+	var mul, shift int16
+	var deviation float64
+	for m := 1; ; m++ {
+		if m > math.MaxInt16 {
+			return nil, fmt.Errorf("%w: [%v, %v]", ErrRangeNotRepresentable, lo, hi)
+		}
+
+		center := math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round((lo+hi)/2/float64(m))))
+		deviation = math.Max(hi/float64(m)-center, center-lo/float64(m))
+		if deviation <= math.MaxInt16 {
+			mul, shift = int16(m), int16(center)
+			break
+		}
+	}
+
+	// Pick the finest step Scale * 10^DecPlaces allows for the deviation
+	scale, decPlaces := int16(1), int16(0)
+	if deviation > 0 {
+		best := 1.0
+		limit := math.MaxInt16 / deviation
+		for d := 0; d <= MaxDecPlaces; d++ {
+			s := math.Min(math.MaxInt16, math.Floor(limit/math.Pow10(d)))
+			if s < 1 {
+				break
+			}
+			if s*math.Pow10(d) > best {
+				best = s * math.Pow10(d)
+				scale, decPlaces = int16(s), int16(d)
+			}
+		}
+	}
+
+	quantized.Shift, quantized.Mul, quantized.Scale, quantized.DecPlaces = shift, mul, scale, decPlaces
+
+	// The scaling is chosen to fit the range, so only rounding at the bounds
+	// can be clamped
+	quantizeInto(data, *c.Data, shift, mul, scale, decPlaces)
+
+	return quantized, nil
+}
+
+// NewScaledInt16Channel builds an int16 channel storing values with the given
+// scaling fields.
+//
+// Each value is converted to the raw sample that ScaledValues maps back to it,
+// rounded to the nearest integer. Raw samples outside the int16 range would
+// wrap around into wild spikes, so they are clamped to the nearest bound
+// instead, and NaN values are stored as 0. The number of clamped or NaN
+// samples is returned: callers that must not lose data should treat a non-zero
+// count as an error, or use QuantizeToInt16 to choose a scaling that fits.
+//
+// Example:
+//
+//	// Oil pressure in bar with two decimal places
+//	pressure, clamped := motecldparser.NewScaledInt16Channel("Oil Pressure", "bar", 50, values, 0, 1, 1, 2)
+func NewScaledInt16Channel(name, unit string, freq uint16, values []float32, shift, mul, scale, decPlaces int16) (*Channel[int16], int) {
+	data := make([]int16, len(values))
+	clamped := quantizeInto(data, values, shift, mul, scale, decPlaces)
+
+	return &Channel[int16]{
+		Frequency: freq,
+		Name:      name,
+		Unit:      unit,
+		Data:      &data,
+		Shift:     shift,
+		Mul:       mul,
+		Scale:     scale,
+		DecPlaces: decPlaces,
+	}, clamped
+}
+
+// quantizeInto converts values to raw int16 samples with the given scaling,
+// clamping them to the int16 range. Returns the number of clamped or NaN
+// samples.
+func quantizeInto(dst []int16, values []float32, shift, mul, scale, decPlaces int16) int {
+	shiftValue, factor := scaling(shift, mul, scale, decPlaces)
+
+	clamped := 0
+	for i, v := range values {
+		var ok bool
+		if dst[i], ok = roundToInt16((float64(v) - shiftValue) / factor); !ok {
+			clamped++
+		}
+	}
+	return clamped
+}
+
+// scaling returns the offset and factor converting raw samples to physical
+// values, so that value = raw*factor + shift.
+func scaling(shift, mul, scale, decPlaces int16) (float64, float64) {
+	m := float64(mul)
+	if m == 0 {
+		m = 1
+	}
+
+	s := float64(scale)
+	if s == 0 {
+		s = 1
+	}
+
+	return float64(shift) * m, m / s * math.Pow10(-int(decPlaces))
+}
+
+// StoragePreference selects the sample type used by File.AddPhysicalChannel.
+type StoragePreference int
+
+const (
+	// StorageAuto stores the channel as int16 when the scaled samples
+	// reproduce every value within AutoStorageTolerance, and as float32
+	// otherwise.
+	StorageAuto StoragePreference = iota
+
+	// StorageCompactInt always stores the channel as scaled int16 samples,
+	// using half the space of float32 at the cost of precision.
+	StorageCompactInt
+
+	// StorageFloat32 always stores the channel as float32 samples.
+	StorageFloat32
+)
+
+// AutoStorageTolerance is the largest reconstruction error accepted by
+// StorageAuto for int16 storage, relative to the largest absolute value of the
+// channel.
+var AutoStorageTolerance = 1e-5
+
+// AddPhysicalChannel adds a channel holding values in physical units, letting
+// the package choose how they are stored.
+//
+// With StorageCompactInt the values are stored as int16 samples with the
+// scaling chosen by QuantizeToInt16, and its errors are returned. With
+// StorageAuto the same int16 representation is used only if it reproduces
+// every value within AutoStorageTolerance, which is typically the case for
+// values with few significant digits such as gears or switch positions; other
+// channels, and channels holding NaN or infinite values, are stored as
+// float32. Either way ScaledValues returns the physical values.
+//
+// Example:
+//
+//	err := file.AddPhysicalChannel("Gear", "", 10, gears, motecldparser.StorageAuto)
+func (f *File) AddPhysicalChannel(name, unit string, freq uint16, values []float64, prefer StoragePreference) error {
+	data := make([]float32, len(values))
+	for i, v := range values {
+		data[i] = float32(v)
+	}
+	channel := &Channel[float32]{Frequency: freq, Name: name, Unit: unit, Data: &data}
+
+	if prefer == StorageFloat32 {
+		f.AddChannels(channel)
+		return nil
+	}
+
+	quantized, err := QuantizeToInt16(channel)
+	if err != nil {
+		if prefer == StorageCompactInt {
+			return fmt.Errorf("channel %q: %w", name, err)
+		}
+		f.AddChannels(channel)
+		return nil
+	}
+
+	if prefer == StorageAuto {
+		largest, worst := 0.0, 0.0
+		for i, v := range quantized.ScaledValues() {
+			largest = max(largest, math.Abs(float64(data[i])))
+			worst = max(worst, math.Abs(v-float64(data[i])))
+		}
+		if worst > AutoStorageTolerance*largest {
+			f.AddChannels(channel)
+			return nil
+		}
+	}
+
+	f.AddChannels(quantized)
+	return nil
+}
+
+// SetAllDecPlaces sets DecPlaces to n on every float32 channel of the file,
+// so their values are displayed with n decimal places.
+//
+// DecPlaces is part of the scaling (see ScaledValues), so the samples of each
+// float32 channel are multiplied by 10^(n - DecPlaces) to keep the physical
+// values unchanged, up to float32 rounding. The samples are rescaled into a
+// new slice: the caller's data, which NewChannel and FromTemplates do not
+// copy, is left untouched.
+//
+// Integer channels are never changed: their DecPlaces sets their resolution
+// and range, which must be chosen per channel (see QuantizeToInt16). Stream
+// channels are skipped as well.
+//
+// Returns ErrInvalidDecPlaces if n is outside [0, MaxDecPlaces], and
+// ErrRangeNotRepresentable if a rescaled sample would overflow float32 or
+// round to zero; the file is not modified in either case.
+func (f *File) SetAllDecPlaces(n int16) error {
+	if n < 0 || n > MaxDecPlaces {
+		return fmt.Errorf("%w: %d", ErrInvalidDecPlaces, n)
+	}
+
+	// Rescale every channel before changing any, so an error leaves the
+	// file as it was
+	var channels []*Channel[float32]
+	var rescaled [][]float32
+	for _, channel := range f.Channels {
+		c, ok := channel.(*Channel[float32])
+		if !ok || c.DecPlaces == n {
+			continue
+		}
+
+		var data []float32
+		if c.Data != nil {
+			factor := math.Pow10(int(n) - int(c.DecPlaces))
+			data = make([]float32, len(*c.Data))
+			for i, v := range *c.Data {
+				data[i] = float32(float64(v) * factor)
+				if (math.IsInf(float64(data[i]), 0) && !math.IsInf(float64(v), 0)) || (data[i] == 0 && v != 0) {
+					return fmt.Errorf("channel %q: %w: sample %d is %v with %d decimal places", c.Name, ErrRangeNotRepresentable, i, v, c.DecPlaces)
+				}
+			}
+		}
+		channels = append(channels, c)
+		rescaled = append(rescaled, data)
+	}
+
+	for i, c := range channels {
+		if c.Data != nil {
+			c.Data = &rescaled[i]
+		}
+		c.DecPlaces = n
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package motecldparser
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestScaledValues(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Channel[int16]
+		want []float64
+	}{
+		{name: "unscaled", c: &Channel[int16]{Data: &[]int16{1, -2}}, want: []float64{1, -2}},
+		{name: "decimal places", c: &Channel[int16]{DecPlaces: 2, Data: &[]int16{150}}, want: []float64{1.5}},
+		{name: "all fields", c: &Channel[int16]{Shift: 10, Mul: 2, Scale: 4, DecPlaces: 1, Data: &[]int16{40}}, want: []float64{22}},
+		{name: "nil data", c: &Channel[int16]{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		got := tt.c.ScaledValues()
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestQuantizeToInt16(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []float32
+		wantErr error
+	}{
+		{name: "small range", data: []float32{0.5, 1.25, -3.75}},
+		{name: "offset range", data: []float32{1000.1, 1000.2, 1000.3}},
+		{name: "wide range", data: []float32{-1e6, 1e6}},
+		{name: "constant", data: []float32{42, 42}},
+		{name: "empty", data: []float32{}},
+		{name: "NaN", data: []float32{1, float32(math.NaN())}, wantErr: ErrNonFiniteValue},
+		{name: "infinite", data: []float32{float32(math.Inf(1))}, wantErr: ErrNonFiniteValue},
+		{name: "too wide", data: []float32{-1e15, 1e15}, wantErr: ErrRangeNotRepresentable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Channel[float32]{Frequency: 10, Name: "Pressure", Unit: "bar", Data: &tt.data}
+			q, err := QuantizeToInt16(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if q.Name != "Pressure" || q.Unit != "bar" || q.Frequency != 10 {
+				t.Errorf("metadata not preserved: %+v", q)
+			}
+			_, step := scaling(q.Shift, q.Mul, q.Scale, q.DecPlaces)
+			for i, v := range q.ScaledValues() {
+				if math.Abs(v-float64(tt.data[i])) > step/2+1e-9*math.Abs(v) {
+					t.Errorf("sample %d: %v restored as %v, step %v", i, tt.data[i], v, step)
+				}
+			}
+		})
+	}
+}
+
+func TestNewScaledInt16Channel(t *testing.T) {
+	c, clamped := NewScaledInt16Channel("Oil Pressure", "bar", 50, []float32{1.234, -2, 400, float32(math.NaN())}, 0, 1, 1, 2)
+
+	if want := []int16{123, -200, math.MaxInt16, 0}; !slices.Equal(*c.Data, want) {
+		t.Errorf("got %v, want %v", *c.Data, want)
+	}
+	if clamped != 2 {
+		t.Errorf("clamped = %d, want 2", clamped)
+	}
+	if c.Name != "Oil Pressure" || c.Unit != "bar" || c.Frequency != 50 || c.DecPlaces != 2 || c.Mul != 1 {
+		t.Errorf("channel = %+v", c)
+	}
+}
+
+func TestAddPhysicalChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		prefer   StoragePreference
+		wantType string
+		wantErr  error
+	}{
+		{name: "auto with few digits", values: []float64{1, 2, 3, 4}, prefer: StorageAuto, wantType: "int16"},
+		{name: "auto with many digits", values: []float64{-12345.678, 12345.678, math.Pi}, prefer: StorageAuto, wantType: "float32"},
+		{name: "auto with NaN", values: []float64{1, math.NaN()}, prefer: StorageAuto, wantType: "float32"},
+		{name: "compact", values: []float64{math.Pi, math.E * 1000}, prefer: StorageCompactInt, wantType: "int16"},
+		{name: "compact with NaN", values: []float64{1, math.NaN()}, prefer: StorageCompactInt, wantErr: ErrNonFiniteValue},
+		{name: "float32", values: []float64{1, 2}, prefer: StorageFloat32, wantType: "float32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{}
+			err := f.AddPhysicalChannel("Value", "unit", 10, tt.values, tt.prefer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(f.Channels) != 0 {
+					t.Error("channel added on error")
+				}
+				return
+			}
+
+			var gotType string
+			switch f.Channels[0].(type) {
+			case *Channel[int16]:
+				gotType = "int16"
+			case *Channel[float32]:
+				gotType = "float32"
+			}
+			if gotType != tt.wantType {
+				t.Errorf("stored as %s, want %s", gotType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestSetAllDecPlaces(t *testing.T) {
+	samples := []float32{1.5, 20}
+	speed := &Channel[float32]{Name: "Speed", Data: &samples}
+	gear := &Channel[int16]{Name: "Gear", Data: &[]int16{3}}
+	empty := &Channel[float32]{Name: "Empty", DecPlaces: 1}
+	f := &File{}
+	f.AddChannels(speed, gear, empty)
+
+	if err := f.SetAllDecPlaces(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if speed.DecPlaces != 2 || !slices.Equal(*speed.Data, []float32{150, 2000}) {
+		t.Errorf("speed: %d decimal places, %v", speed.DecPlaces, *speed.Data)
+	}
+	if values := speed.ScaledValues(); values[0] != 1.5 {
+		t.Errorf("physical values changed: %v", values)
+	}
+	if !slices.Equal(samples, []float32{1.5, 20}) {
+		t.Errorf("caller data modified: %v", samples)
+	}
+	if gear.DecPlaces != 0 || (*gear.Data)[0] != 3 {
+		t.Errorf("integer channel modified: %+v", gear)
+	}
+	if empty.DecPlaces != 2 {
+		t.Errorf("empty channel: %d decimal places", empty.DecPlaces)
+	}
+}
+
+func TestSetAllDecPlacesErrors(t *testing.T) {
+	for _, n := range []int16{-1, MaxDecPlaces + 1} {
+		if err := (&File{}).SetAllDecPlaces(n); !errors.Is(err, ErrInvalidDecPlaces) {
+			t.Errorf("%d: got %v, want ErrInvalidDecPlaces", n, err)
+		}
+	}
+
+	// A channel read with a large DecPlaces cannot be brought back to 0
+	// without overflowing, and neither channel is changed
+	small := &Channel[float32]{Name: "Small", Data: &[]float32{1}}
+	huge := &Channel[float32]{Name: "Huge", DecPlaces: 30000, Data: &[]float32{1}}
+	f := &File{}
+	f.AddChannels(small, huge)
+	if err := f.SetAllDecPlaces(0); !errors.Is(err, ErrRangeNotRepresentable) {
+		t.Errorf("got %v, want ErrRangeNotRepresentable", err)
+	}
+	if huge.DecPlaces != 30000 || (*huge.Data)[0] != 1 {
+		t.Errorf("channel modified: %+v", huge)
+	}
+
+	// Values too small for the new scale would round to zero
+	f = &File{}
+	f.AddChannels(&Channel[float32]{Name: "Tiny", DecPlaces: 9, Data: &[]float32{1e-40}})
+	if err := f.SetAllDecPlaces(0); !errors.Is(err, ErrRangeNotRepresentable) {
+		t.Errorf("got %v, want ErrRangeNotRepresentable", err)
+	}
+}
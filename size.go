@@ -0,0 +1,87 @@
+package motecldparser
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+// Sizes in bytes of the fixed blocks of a file. binary.Size relies on
+// reflection, so they are computed once instead of on every write.
+var (
+	headSize        = int64(binary.Size(ldfile.LdFileHead{}))
+	eventSize       = int64(binary.Size(ldfile.LdFileEvent{}))
+	venueSize       = int64(binary.Size(ldfile.LdFileVenue{}))
+	vehicleSize     = int64(binary.Size(ldfile.LdFileVehicle{}))
+	channelMetaSize = int64(binary.Size(ldfile.LdFileChannelMeta{}))
+)
+
+// ErrUnknownSize is returned when the size of a channel is not known before
+// writing.
+var ErrUnknownSize = errors.New("motecldparser: channel size not known before writing")
+
+// ChannelSpec describes the shape of a channel for size estimation.
+//
+// DataTypeLength is the size in bytes of a single sample (see the
+// ldfile.DataType values) and SampleCount is the number of samples the
+// channel will hold.
+type ChannelSpec struct {
+	DataTypeLength uint16 // Size in bytes of each sample (2 or 4)
+	SampleCount    int    // Number of samples in the channel
+}
+
+// EstimateSize returns the size in bytes of an LD file containing the given
+// channels.
+//
+// The estimate includes the header, event, venue and vehicle blocks, one
+// metadata block per channel and the channel data. It matches the number of
+// bytes written by File.Write for channels of the same shape, so it can be
+// used to report the expected output size before any data is collected.
+//
+// Example:
+//
+//	size := motecldparser.EstimateSize([]motecldparser.ChannelSpec{
+//	    {DataTypeLength: 4, SampleCount: 100 * 3600}, // 1 hour at 100 Hz
+//	    {DataTypeLength: 2, SampleCount: 10 * 3600},  // 1 hour at 10 Hz
+//	})
+func EstimateSize(channels []ChannelSpec) int64 {
+	size := headSize + eventSize + venueSize + vehicleSize
+	size += channelMetaSize * int64(len(channels))
+
+	for _, channel := range channels {
+		size += int64(channel.DataTypeLength) * int64(channel.SampleCount)
+	}
+
+	return size
+}
+
+// Size returns the size in bytes of the file as written by File.Write.
+//
+// Returns ErrUnknownSize if the file contains a StreamChannel, whose length is
+// only known once its reader has been drained.
+func (f *File) Size() (int64, error) {
+	specs := make([]ChannelSpec, len(f.Channels))
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		spec, ok := c.spec()
+		if !ok {
+			return 0, ErrUnknownSize
+		}
+		specs[i] = spec
+	}
+
+	return EstimateSize(specs), nil
+}
+
+func (c *Channel[T]) spec() (ChannelSpec, bool) {
+	return ChannelSpec{DataTypeLength: dataTypeOf[T]().DataTypeLength, SampleCount: c.SampleCount()}, true
+}
+
+func (c *StreamChannel[T]) spec() (ChannelSpec, bool) {
+	return ChannelSpec{}, false
+}
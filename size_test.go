@@ -0,0 +1,110 @@
+package motecldparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+func TestEstimateSize(t *testing.T) {
+	manyChannels := func() *File {
+		f := &File{}
+		for i := 0; i < 50; i++ {
+			data := make([]int32, i*7)
+			f.AddChannels(&Channel[int32]{Frequency: 10, Name: fmt.Sprintf("Channel %d", i), Data: &data})
+		}
+		return f
+	}
+
+	tests := []struct {
+		name string
+		file func() *File
+	}{
+		{name: "no channels", file: func() *File { return &File{} }},
+		{name: "two channels", file: twoChannelFile},
+		{name: "three channels", file: threeChannelFile},
+		{name: "empty channel", file: func() *File {
+			f := &File{}
+			f.AddChannels(&Channel[int16]{Frequency: 10, Name: "Empty", Data: &[]int16{}})
+			return f
+		}},
+		{name: "many channels", file: manyChannels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.file()
+
+			var specs []ChannelSpec
+			for _, c := range f.AnyChannels() {
+				spec, _ := c.spec()
+				specs = append(specs, spec)
+			}
+			estimate := EstimateSize(specs)
+
+			size, err := f.Size()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != estimate {
+				t.Errorf("Size = %d, EstimateSize = %d", size, estimate)
+			}
+
+			if written := int64(len(writeBytes(t, f))); written != estimate {
+				t.Errorf("estimated %d bytes, wrote %d", estimate, written)
+			}
+		})
+	}
+}
+
+func TestSizeStream(t *testing.T) {
+	f := twoChannelFile()
+	f.AddChannels(&StreamChannel[float32]{Frequency: 10, Name: "Stream", Reader: strings.NewReader("")})
+
+	if _, err := f.Size(); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("got %v, want ErrUnknownSize", err)
+	}
+}
+
+// BenchmarkBlockSizes compares computing the sizes of the fixed blocks with
+// binary.Size on every write against the values cached by the package.
+func BenchmarkBlockSizes(b *testing.B) {
+	b.Run("binary.Size", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = int64(binary.Size(ldfile.LdFileHead{})) + int64(binary.Size(ldfile.LdFileEvent{})) +
+				int64(binary.Size(ldfile.LdFileVenue{})) + int64(binary.Size(ldfile.LdFileVehicle{})) +
+				int64(binary.Size(ldfile.LdFileChannelMeta{}))
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = EstimateSize(nil)
+		}
+	})
+}
+
+// BenchmarkWriteSmallFiles writes many small files, where the cost of the
+// fixed blocks dominates.
+func BenchmarkWriteSmallFiles(b *testing.B) {
+	f := twoChannelFile()
+	size, err := f.Size()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(size))
+	b.SetBytes(size)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if _, err := f.WriteTo(&buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package motecldparser
+
+import (
+	"fmt"
+	"io"
+)
+
+// StreamChannel is a channel whose samples are read from an io.Reader while
+// the file is being written.
+//
+// It is intended for channels too large to hold in memory. The reader must
+// yield little-endian samples of type T, exactly as they are stored in the LD
+// file. The number of samples is not known in advance: it is counted while
+// the data is copied and stored in the channel metadata afterwards.
+//
+// The reader is consumed by File.Write, so a StreamChannel can only be written
+// once.
+//
+// Example:
+//
+//	src, err := os.Open("speed.bin")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer src.Close()
+//	file.AddChannels(&StreamChannel[float32]{
+//	    Frequency: 100,
+//	    Name:      "Speed",
+//	    Unit:      "km/h",
+//	    Reader:    src,
+//	})
+type StreamChannel[T float32 | int16 | int32] struct {
+	Frequency uint16    // Sampling frequency in Hz
+	Name      string    // Full channel name
+	ShortName string    // Abbreviated name (displayed in compact views)
+	Unit      string    // Unit of measurement (e.g., "km/h", "rpm", "°C")
+	Reader    io.Reader // Source of little-endian encoded samples
+
+	samples int // Number of samples copied by the last write
+}
+
+// ChannelName returns the full name of the channel.
+func (c *StreamChannel[T]) ChannelName() string {
+	return c.Name
+}
+
+// ChannelShortName returns the abbreviated name of the channel.
+func (c *StreamChannel[T]) ChannelShortName() string {
+	return c.ShortName
+}
+
+// ChannelUnit returns the unit of measurement of the channel.
+func (c *StreamChannel[T]) ChannelUnit() string {
+	return c.Unit
+}
+
+// ChannelFrequency returns the sampling frequency of the channel in Hz.
+func (c *StreamChannel[T]) ChannelFrequency() uint16 {
+	return c.Frequency
+}
+
+// SampleCount returns the number of samples copied from the reader.
+//
+// The count is only known after the channel has been written and is zero
+// before that.
+func (c *StreamChannel[T]) SampleCount() int {
+	return c.samples
+}
+
+// Write copies the channel's samples from its reader to the file and then
+// writes the channel metadata.
+//
+// The parameters and return values are the same as for Channel.Write. An
+// error is returned if the reader fails or if it yields a number of bytes
+// that is not a whole number of samples.
+//
+// This method should not typically be called directly by users.
+func (c *StreamChannel[T]) Write(
+	w io.WriteSeeker,
+	n uint16,
+	channelsCount uint32,
+	channelsMetaPointer uintptr,
+	currentDataPointer uintptr,
+) (uintptr, error) {
+	dataType := dataTypeOf[T]()
+	currentMetaPointer, channelMeta := newChannelMeta(dataType, n, channelsCount, channelsMetaPointer, currentDataPointer)
+
+	if c.Reader == nil {
+		return 0, fmt.Errorf("stream channel %q has no reader", c.Name)
+	}
+
+	// Copy the data first, as its length is only known once the reader is drained
+	if _, err := w.Seek(int64(currentDataPointer), io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek to channel data at %d: %w", currentDataPointer, err)
+	}
+
+	written, err := io.Copy(w, c.Reader)
+	if err != nil {
+		return 0, fmt.Errorf("copy stream channel %q: %w", c.Name, err)
+	}
+
+	if written%int64(dataType.DataTypeLength) != 0 {
+		return 0, fmt.Errorf("stream channel %q: %d bytes is not a whole number of %d-byte samples", c.Name, written, dataType.DataTypeLength)
+	}
+
+	c.samples = int(written / int64(dataType.DataTypeLength))
+
+	channelMeta.DataLength = uint32(c.samples)
+	channelMeta.Frequency = c.Frequency
+
+	copy(channelMeta.Name[:], c.Name)
+	copy(channelMeta.ShortName[:], c.ShortName)
+	copy(channelMeta.Unit[:], c.Unit)
+
+	if err := writeAt(w, currentMetaPointer, channelMeta); err != nil {
+		return 0, fmt.Errorf("write metadata: %w", err)
+	}
+
+	return currentDataPointer + uintptr(written), nil
+}
+
+func (c *StreamChannel[T]) write(w io.WriteSeeker, n uint16, channelsCount uint32, channelsMetaPointer uintptr, currentDataPointer uintptr) (uintptr, error) {
+	return c.Write(w, n, channelsCount, channelsMetaPointer, currentDataPointer)
+}
@@ -0,0 +1,62 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamChannel(t *testing.T) {
+	stream := &StreamChannel[int16]{Frequency: 5, Name: "Gear", Unit: "gear", Reader: bytes.NewReader([]byte{1, 0, 2, 0, 0xFF, 0xFF})}
+	f := &File{}
+	f.AddChannels(stream, &Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{1, 2}})
+
+	if stream.SampleCount() != 0 {
+		t.Errorf("sample count before writing = %d", stream.SampleCount())
+	}
+	read := roundTrip(t, f)
+	if stream.SampleCount() != 3 {
+		t.Errorf("sample count after writing = %d, want 3", stream.SampleCount())
+	}
+
+	gear := read.Channels[0].(*Channel[int16])
+	if gear.Name != "Gear" || gear.Unit != "gear" || gear.Frequency != 5 || !slices.Equal(*gear.Data, []int16{1, 2, -1}) {
+		t.Errorf("gear = %+v %v", gear, *gear.Data)
+	}
+	if speed := read.Channels[1].(*Channel[float32]); !slices.Equal(*speed.Data, []float32{1, 2}) {
+		t.Errorf("speed after the stream = %v", *speed.Data)
+	}
+}
+
+func TestStreamChannelErrors(t *testing.T) {
+	readErr := errors.New("read failed")
+
+	tests := []struct {
+		name    string
+		reader  io.Reader
+		wantErr error
+	}{
+		{name: "no reader"},
+		{name: "partial sample", reader: bytes.NewReader([]byte{1, 0, 0})},
+		{name: "reader error", reader: iotest.ErrReader(readErr), wantErr: readErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{}
+			f.AddChannels(&StreamChannel[float32]{Frequency: 1, Name: "Stream", Reader: tt.reader})
+
+			var buf bytes.Buffer
+			_, err := f.WriteTo(&buf)
+			if err == nil {
+				t.Fatal("got nil error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package motecldparser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FromStructSlice builds a File from a slice of structs, one channel per
+// tagged field.
+//
+// rows must be a slice of structs or of pointers to structs, none of them
+// nil. Each element is one sample of every channel. Fields with a `motec` tag
+// become channels; other fields are ignored. The tag is a comma-separated list
+// of key=value options:
+//   - name: channel name (defaults to the field name)
+//   - short: channel short name
+//   - unit: unit of measurement
+//   - freq: sampling frequency in Hz (required)
+//
+// Fields of type float32, int16 and int32 become channels of the same type;
+// float64 fields become float32 channels. Any other field type is reported
+// with ErrUnsupportedDataType. Channels are added in field order and session
+// metadata is left empty for the caller to fill in.
+//
+// Example:
+//
+//	type sample struct {
+//	    Speed float32 `motec:"name=Speed,unit=km/h,freq=100"`
+//	    Gear  int16   `motec:"name=Gear,short=GEAR,freq=100"`
+//	}
+//	file, err := motecldparser.FromStructSlice([]sample{{10.5, 2}, {12.1, 3}})
+func FromStructSlice(rows any) (*File, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("motecldparser: FromStructSlice needs a slice of structs, got %T", rows)
+	}
+
+	elem := v.Type().Elem()
+	pointers := elem.Kind() == reflect.Pointer
+	if pointers {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("motecldparser: FromStructSlice needs a slice of structs, got %T", rows)
+	}
+
+	if pointers {
+		for j := 0; j < v.Len(); j++ {
+			if v.Index(j).IsNil() {
+				return nil, fmt.Errorf("motecldparser: FromStructSlice: row %d is nil", j)
+			}
+		}
+	}
+
+	f := &File{}
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		tag, ok := field.Tag.Lookup("motec")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		template, err := parseMotecTag(field.Name, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		column := func(j int) reflect.Value {
+			row := v.Index(j)
+			if pointers {
+				row = row.Elem()
+			}
+			return row.Field(i)
+		}
+
+		var data any
+		switch field.Type.Kind() {
+		case reflect.Float32, reflect.Float64:
+			samples := make([]float32, v.Len())
+			for j := range samples {
+				samples[j] = float32(column(j).Float())
+			}
+			data = samples
+		case reflect.Int16:
+			samples := make([]int16, v.Len())
+			for j := range samples {
+				samples[j] = int16(column(j).Int())
+			}
+			data = samples
+		case reflect.Int32:
+			samples := make([]int32, v.Len())
+			for j := range samples {
+				samples[j] = int32(column(j).Int())
+			}
+			data = samples
+		default:
+			return nil, fmt.Errorf("%w: field %s has type %s", ErrUnsupportedDataType, field.Name, field.Type)
+		}
+
+		channel, err := template.Channel(data)
+		if err != nil {
+			return nil, err
+		}
+		f.AddChannels(channel)
+	}
+
+	return f, nil
+}
+
+// parseMotecTag decodes the `motec` struct tag of a field.
+func parseMotecTag(fieldName, tag string) (ChannelTemplate, error) {
+	template := ChannelTemplate{Name: fieldName}
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		switch strings.TrimSpace(key) {
+		case "name":
+			template.Name = value
+		case "short":
+			template.ShortName = value
+		case "unit":
+			template.Unit = value
+		case "freq":
+			freq, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return template, fmt.Errorf("motecldparser: field %s: invalid frequency %q", fieldName, value)
+			}
+			template.Frequency = uint16(freq)
+		default:
+			return template, fmt.Errorf("motecldparser: field %s: unknown tag option %q", fieldName, option)
+		}
+	}
+
+	if template.Frequency == 0 {
+		return template, fmt.Errorf("motecldparser: field %s: missing freq in tag", fieldName)
+	}
+
+	return template, nil
+}
@@ -0,0 +1,86 @@
+package motecldparser
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type structSample struct {
+	Speed    float64 `motec:"name=Speed,unit=km/h,freq=100"`
+	Gear     int16   `motec:"short=GEAR,freq=10"`
+	Ignored  string
+	Distance int32 `motec:"freq=100"`
+}
+
+func TestFromStructSlice(t *testing.T) {
+	rows := []structSample{{10.5, 2, "a", 1}, {12, 3, "b", 2}}
+
+	for _, input := range []any{rows, []*structSample{&rows[0], &rows[1]}} {
+		f, err := FromStructSlice(input)
+		if err != nil {
+			t.Fatalf("%T: %v", input, err)
+		}
+
+		infos := f.ChannelInfos()
+		if len(infos) != 3 {
+			t.Fatalf("%T: got %d channels, want 3", input, len(infos))
+		}
+
+		speed := f.Channels[0].(*Channel[float32])
+		if speed.Name != "Speed" || speed.Unit != "km/h" || speed.Frequency != 100 || !slices.Equal(*speed.Data, []float32{10.5, 12}) {
+			t.Errorf("%T: speed = %+v %v", input, speed, *speed.Data)
+		}
+
+		gear := f.Channels[1].(*Channel[int16])
+		if gear.Name != "Gear" || gear.ShortName != "GEAR" || !slices.Equal(*gear.Data, []int16{2, 3}) {
+			t.Errorf("%T: gear = %+v %v", input, gear, *gear.Data)
+		}
+
+		if _, ok := f.Channels[2].(*Channel[int32]); !ok {
+			t.Errorf("%T: distance is %T", input, f.Channels[2])
+		}
+	}
+}
+
+func TestFromStructSliceErrors(t *testing.T) {
+	type unsupported struct {
+		Name string `motec:"freq=10"`
+	}
+	type noFrequency struct {
+		Speed float32 `motec:"name=Speed"`
+	}
+	type unknownOption struct {
+		Speed float32 `motec:"freq=10,color=red"`
+	}
+
+	tests := []struct {
+		name    string
+		rows    any
+		wantErr error
+		wantMsg string
+	}{
+		{name: "not a slice", rows: structSample{}, wantMsg: "slice of structs"},
+		{name: "slice of ints", rows: []int{1}, wantMsg: "slice of structs"},
+		{name: "nil row", rows: []*structSample{{}, nil}, wantMsg: "row 1 is nil"},
+		{name: "unsupported field", rows: []unsupported{{}}, wantErr: ErrUnsupportedDataType},
+		{name: "missing frequency", rows: []noFrequency{{}}, wantMsg: "missing freq"},
+		{name: "unknown option", rows: []unknownOption{{}}, wantMsg: "unknown tag option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromStructSlice(tt.rows)
+			if err == nil {
+				t.Fatal("got nil error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("got %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
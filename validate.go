@@ -0,0 +1,460 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var (
+	// ErrUnknownUnit is reported by File.CheckUnits for units missing from KnownUnits.
+	ErrUnknownUnit = errors.New("motecldparser: unknown unit")
+
+	// ErrStringTooLong is reported when a string does not fit its field in the file.
+	ErrStringTooLong = errors.New("motecldparser: string too long")
+
+	// ErrEmptyName is reported for channels without a name.
+	ErrEmptyName = errors.New("motecldparser: empty channel name")
+
+	// ErrDuplicateName is reported when two channels share the same name.
+	ErrDuplicateName = errors.New("motecldparser: duplicate channel name")
+
+	// ErrDuplicateShortName is reported when two channels share the same short name.
+	ErrDuplicateShortName = errors.New("motecldparser: duplicate channel short name")
+
+	// ErrInvalidFrequency is reported for channels with a zero frequency.
+	ErrInvalidFrequency = errors.New("motecldparser: invalid channel frequency")
+
+	// ErrNilData is reported for channels without a data source.
+	ErrNilData = errors.New("motecldparser: channel has no data")
+
+	// ErrNonFiniteValue is reported for float channels containing NaN or infinite samples.
+	ErrNonFiniteValue = errors.New("motecldparser: non-finite sample value")
+
+	// ErrFrequencyNotDivisor is reported by File.CheckFrequencyDivisors.
+	ErrFrequencyNotDivisor = errors.New("motecldparser: frequency does not divide the master rate")
+
+	// ErrSizeMismatch is returned by File.WriteStrict when the written file
+	// does not have the expected size.
+	ErrSizeMismatch = errors.New("motecldparser: written size does not match the layout")
+
+	// ErrImplausibleWeight is reported by File.CheckVehicleWeight.
+	ErrImplausibleWeight = errors.New("motecldparser: implausible vehicle weight")
+
+	// ErrAliasedData is reported for channels sharing their data with another channel.
+	ErrAliasedData = errors.New("motecldparser: aliased channel data")
+
+	// ErrFractionalDuration is reported by File.CheckDurations.
+	ErrFractionalDuration = errors.New("motecldparser: channel duration is not a whole number of seconds")
+)
+
+// KnownUnits is the set of unit strings accepted by File.CheckUnits.
+//
+// It contains the units most commonly used in MoTeC i2. Add entries to accept
+// project-specific units. An empty unit is always accepted.
+var KnownUnits = map[string]bool{
+	// Speed and acceleration
+	"km/h": true, "mph": true, "m/s": true, "m/s/s": true, "G": true,
+	// Rotation and angles
+	"rpm": true, "deg": true, "rad": true, "deg/s": true, "rad/s": true,
+	// Distance and time
+	"m": true, "km": true, "mm": true, "mi": true, "s": true, "ms": true, "us": true,
+	// Temperature
+	"°C": true, "°F": true, "K": true,
+	// Pressure
+	"kPa": true, "bar": true, "psi": true, "mbar": true,
+	// Electrical
+	"V": true, "mV": true, "A": true, "mA": true, "Hz": true,
+	// Force, torque and power
+	"N": true, "Nm": true, "kW": true, "hp": true,
+	// Mass, volume and flow
+	"kg": true, "l": true, "ml": true, "l/h": true, "g/s": true,
+	// Dimensionless
+	"%": true, "lambda": true, "ratio": true, "gear": true,
+}
+
+// CheckUnits reports the channels whose unit is not in KnownUnits.
+//
+// A misspelled unit (e.g. "kmh" instead of "km/h") is shown as a custom unit
+// in i2 and prevents unit conversion in math channels. The check is advisory:
+// it does not modify the file and does not prevent writing it.
+//
+// Returns one *FieldError wrapping ErrUnknownUnit per offending channel, or
+// nil if all units are known.
+func (f *File) CheckUnits() []error {
+	var errs []error
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		unit := c.ChannelUnit()
+		if unit != "" && !KnownUnits[unit] {
+			errs = append(errs, &FieldError{Channel: i, Field: "Unit", Reason: fmt.Sprintf("%q", unit), Err: ErrUnknownUnit})
+		}
+	}
+	return errs
+}
+
+// CheckFrequencyDivisors reports the channels whose frequency is not an
+// integer divisor of masterHz.
+//
+// The LD format stores the absolute frequency of each channel; there is no
+// master rate or divisor field. Loggers do sample every channel at a divisor
+// of a master rate, though, and channels that follow this rule share sample
+// instants, which keeps them aligned on the common time base of i2. The check
+// is advisory: it does not modify the file and does not prevent writing it.
+//
+// Returns one *FieldError wrapping ErrFrequencyNotDivisor per offending
+// channel, or nil if every frequency divides masterHz. Channels with a zero
+// frequency are left to Validate.
+func (f *File) CheckFrequencyDivisors(masterHz uint16) []error {
+	var errs []error
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok || c.ChannelFrequency() == 0 {
+			continue
+		}
+
+		if masterHz%c.ChannelFrequency() != 0 {
+			errs = append(errs, &FieldError{Channel: i, Field: "Frequency", Reason: fmt.Sprintf("%d Hz with a %d Hz master rate", c.ChannelFrequency(), masterHz), Err: ErrFrequencyNotDivisor})
+		}
+	}
+	return errs
+}
+
+// HasIntegerDuration reports whether the channel covers a whole number of
+// seconds, i.e. whether its sample count is a multiple of its frequency.
+//
+// A fractional duration is usually fine, as logging rarely stops on a second
+// boundary, but can reveal a dropped or duplicated sample when channels are
+// expected to cover the same time. Channels with a zero frequency report
+// false.
+func (c *Channel[T]) HasIntegerDuration() bool {
+	return hasIntegerDuration(c)
+}
+
+// hasIntegerDuration reports whether a channel covers a whole number of
+// seconds.
+func hasIntegerDuration(c AnyChannel) bool {
+	return c.ChannelFrequency() != 0 && c.SampleCount()%int(c.ChannelFrequency()) == 0
+}
+
+// CheckDurations reports the channels whose duration is not a whole number of
+// seconds (see Channel.HasIntegerDuration).
+//
+// The check is informational: such files are valid, and the check does not
+// modify the file or prevent writing it. Returns one *FieldError wrapping
+// ErrFractionalDuration per channel, or nil if every duration is whole.
+// Channels with a zero frequency are left to Validate and stream channels,
+// whose length is only known once written, are skipped.
+func (f *File) CheckDurations() []error {
+	var errs []error
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok || c.ChannelFrequency() == 0 {
+			continue
+		}
+		if _, ok := c.spec(); !ok {
+			continue
+		}
+
+		if !hasIntegerDuration(c) {
+			errs = append(errs, &FieldError{Channel: i, Field: "Data", Reason: fmt.Sprintf("%d samples at %d Hz", c.SampleCount(), c.ChannelFrequency()), Err: ErrFractionalDuration})
+		}
+	}
+	return errs
+}
+
+// MaxVehicleWeight is the heaviest vehicle weight, in kilograms, accepted by
+// File.CheckVehicleWeight.
+const MaxVehicleWeight = 100000
+
+// CheckVehicleWeight reports an implausible VehicleWeight.
+//
+// VehicleWeight is in kilograms. Weights above MaxVehicleWeight are reported;
+// a zero weight means the field was left unset and is accepted. The check is
+// advisory: it does not modify the file and does not prevent writing it, but
+// its result is passed to File.OnWarn when writing.
+//
+// Returns a *FieldError wrapping ErrImplausibleWeight, or nil if the weight is
+// plausible.
+func (f *File) CheckVehicleWeight() error {
+	if f.VehicleWeight > MaxVehicleWeight {
+		return &FieldError{Channel: -1, Field: "VehicleWeight", Reason: fmt.Sprintf("%d kg, max %d", f.VehicleWeight, MaxVehicleWeight), Err: ErrImplausibleWeight}
+	}
+	return nil
+}
+
+// CheckAliasing reports channels whose data is shared with another channel.
+//
+// Two channels alias each other when they use the same Data pointer (e.g.
+// Data: speed.Data copied by mistake) or when their slices overlap in the
+// same backing array. Modifying one of them silently changes the other, and
+// the written file contains duplicated data. The check is advisory and meant
+// for debugging.
+//
+// Returns one *FieldError wrapping ErrAliasedData for each channel sharing
+// data with an earlier channel, or nil if no aliasing is found.
+func (f *File) CheckAliasing() []error {
+	var errs []error
+	spans := make([]dataSpan, len(f.Channels))
+	for i, channel := range f.Channels {
+		span, ok := spanOf(channel)
+		if !ok {
+			continue
+		}
+		spans[i] = span
+
+		for j := 0; j < i; j++ {
+			if spans[j].aliases(span) {
+				errs = append(errs, &FieldError{Channel: i, Field: "Data", Reason: fmt.Sprintf("shared with channel %d", j), Err: ErrAliasedData})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// dataSpan locates the data of a channel in memory.
+type dataSpan struct {
+	header     unsafe.Pointer // Address of the slice header (Channel.Data)
+	start, end uintptr        // Address range of the samples
+}
+
+// aliases reports whether two spans share their slice header or samples.
+func (s dataSpan) aliases(other dataSpan) bool {
+	if s.header == nil || other.header == nil {
+		return false
+	}
+	if s.header == other.header {
+		return true
+	}
+	return s.start < other.end && other.start < s.end
+}
+
+// spanOf returns the memory span of a channel's data, if held in memory.
+func spanOf(channel any) (dataSpan, bool) {
+	switch c := channel.(type) {
+	case *Channel[float32]:
+		return channelSpan(c), true
+	case *Channel[int16]:
+		return channelSpan(c), true
+	case *Channel[int32]:
+		return channelSpan(c), true
+	default:
+		return dataSpan{}, false
+	}
+}
+
+func channelSpan[T float32 | int16 | int32](c *Channel[T]) dataSpan {
+	if c.Data == nil {
+		return dataSpan{}
+	}
+
+	var zero T
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(*c.Data)))
+	return dataSpan{
+		header: unsafe.Pointer(c.Data),
+		start:  start,
+		end:    start + uintptr(len(*c.Data))*unsafe.Sizeof(zero),
+	}
+}
+
+// Validate checks that the file can be written without losing information.
+//
+// The following problems are reported:
+//   - strings longer than their field (see File and Channel for the limits)
+//   - channels without a name, which are hard to select in i2, unless
+//     File.AutoName is set (empty short names and units are allowed)
+//   - channels sharing the same non-empty name
+//   - channels sharing the same non-empty short name, which collide in the i2
+//     views keyed by short name (empty short names are common and allowed)
+//   - channels with a zero frequency
+//   - channels without data
+//   - float channels containing NaN or infinite samples
+//
+// EventComment is checked like every other string: its limit is much larger
+// (1024 bytes) but longer comments are cut just the same. TruncateComment can
+// be used to shorten it safely.
+//
+// Write does not call Validate: strings other than channel short names are
+// truncated and other problems are written as-is, reported only through
+// File.OnWarn if set. Use WriteStrict to refuse writing invalid files.
+//
+// Returns all problems as *FieldError values joined with errors.Join, or nil
+// if the file is valid.
+func (f *File) Validate() error {
+	return errors.Join(f.problems()...)
+}
+
+// problems returns every problem reported by Validate.
+func (f *File) problems() []error {
+	var errs []error
+	for _, err := range []error{
+		checkLength(-1, "Driver", f.Driver, 64),
+		checkLength(-1, "Vehicle", f.Vehicle, 64),
+		checkLength(-1, "Venue", f.Venue, 64),
+		checkLength(-1, "ShortComment", f.ShortComment, 64),
+		checkLength(-1, "EventName", f.EventName, 64),
+		checkLength(-1, "EventSession", f.EventSession, 64),
+		checkLength(-1, "EventComment", f.EventComment, 1024),
+		checkLength(-1, "VehicleId", f.VehicleId, 64),
+		checkLength(-1, "VehicleType", f.VehicleType, 32),
+		checkLength(-1, "VehicleComment", f.VehicleComment, 32),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	names := make(map[string]int)
+	shortNames := make(map[string]int)
+	for i, channel := range f.Channels {
+		c, ok := channel.(AnyChannel)
+		if !ok {
+			continue
+		}
+
+		errs = append(errs, c.validate(i)...)
+
+		name := c.ChannelName()
+		if name == "" && !f.AutoName {
+			errs = append(errs, &FieldError{Channel: i, Field: "Name", Err: ErrEmptyName})
+		}
+		if first, ok := names[name]; ok && name != "" {
+			errs = append(errs, &FieldError{Channel: i, Field: "Name", Reason: fmt.Sprintf("%q is also used by channel %d", name, first), Err: ErrDuplicateName})
+		} else {
+			names[name] = i
+		}
+
+		shortName := c.ChannelShortName()
+		if first, ok := shortNames[shortName]; ok && shortName != "" {
+			errs = append(errs, &FieldError{Channel: i, Field: "ShortName", Reason: fmt.Sprintf("%q is also used by channel %d", shortName, first), Err: ErrDuplicateShortName})
+		} else {
+			shortNames[shortName] = i
+		}
+	}
+
+	return errs
+}
+
+// WriteStrict validates the file and writes it only if no problem is found.
+//
+// It runs Validate and CheckUnits and refuses to write anything if either
+// reports a problem. All problems are returned together, joined with
+// errors.Join, so a single run lists everything that needs fixing. This is
+// intended as a hard gate in automated pipelines.
+//
+// After writing, the size of the destination is checked against the sum of
+// the section sizes (see File.Plan); a difference, which would reveal a gap or
+// a stray write, is reported as ErrSizeMismatch. The destination must
+// therefore be empty, e.g. a newly created or truncated file.
+//
+// Unlike Write, WriteStrict accepts any io.WriteSeeker.
+func (f *File) WriteStrict(w io.WriteSeeker) error {
+	errs := append([]error{f.Validate()}, f.CheckUnits()...)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if err := f.write(w); err != nil {
+		return err
+	}
+
+	// Verify that the destination holds exactly the planned sections
+	end, err := w.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek to end: %w", err)
+	}
+	if end != f.LastWritePlan.Size {
+		return fmt.Errorf("%w: destination holds %d bytes, expected %d", ErrSizeMismatch, end, f.LastWritePlan.Size)
+	}
+
+	return nil
+}
+
+func (c *Channel[T]) validate(n int) []error {
+	errs := validateChannelFields(n, c.Name, c.ShortName, c.Unit, c.Frequency)
+
+	if c.Data == nil {
+		errs = append(errs, &FieldError{Channel: n, Field: "Data", Err: ErrNilData})
+		return errs
+	}
+
+	for i, v := range *c.Data {
+		f := float64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			errs = append(errs, &FieldError{Channel: n, Field: "Data", Reason: fmt.Sprintf("sample %d is %v", i, f), Err: ErrNonFiniteValue})
+			break
+		}
+	}
+
+	return errs
+}
+
+func (c *StreamChannel[T]) validate(n int) []error {
+	errs := validateChannelFields(n, c.Name, c.ShortName, c.Unit, c.Frequency)
+
+	if c.Reader == nil {
+		errs = append(errs, &FieldError{Channel: n, Field: "Reader", Err: ErrNilData})
+	}
+
+	return errs
+}
+
+// validateChannelFields checks the fields shared by all channel kinds.
+func validateChannelFields(n int, name, shortName, unit string, frequency uint16) []error {
+	var errs []error
+	for _, err := range []error{
+		checkLength(n, "Name", name, 32),
+		checkLength(n, "ShortName", shortName, 8),
+		checkLength(n, "Unit", unit, 12),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if frequency == 0 {
+		errs = append(errs, &FieldError{Channel: n, Field: "Frequency", Err: ErrInvalidFrequency})
+	}
+
+	return errs
+}
+
+// checkLength reports a string that does not fit a field of limit bytes.
+//
+// The channel index n is -1 for file fields.
+func checkLength(n int, field, value string, limit int) error {
+	if len(value) > limit {
+		return &FieldError{Channel: n, Field: field, Reason: fmt.Sprintf("%d bytes, max %d, the excess is cut when written", len(value), limit), Err: ErrStringTooLong}
+	}
+	return nil
+}
+
+// TruncateComment shortens s to fit the 1024-byte EventComment field.
+//
+// The string is cut at a character boundary, so multi-byte UTF-8 characters
+// are never split. Strings that already fit are returned unchanged.
+func TruncateComment(s string) string {
+	return truncateString(s, 1024)
+}
+
+// truncateString shortens s to at most limit bytes without splitting a UTF-8
+// encoded character.
+func truncateString(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+
+	end := limit
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
@@ -0,0 +1,278 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fieldErrors returns the *FieldError values joined in err.
+func fieldErrors(t *testing.T, err error) []*FieldError {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	fieldErrs := make([]*FieldError, len(errs))
+	for i, err := range errs {
+		if !errors.As(err, &fieldErrs[i]) {
+			t.Fatalf("%v is not a *FieldError", err)
+		}
+	}
+	return fieldErrs
+}
+
+// problem identifies a *FieldError by its location and sentinel error.
+type problem struct {
+	channel int
+	field   string
+	err     error
+}
+
+// problemsOf returns the problems described by errs.
+func problemsOf(fieldErrs []*FieldError) []problem {
+	var problems []problem
+	for _, e := range fieldErrs {
+		problems = append(problems, problem{e.Channel, e.Field, e.Err})
+	}
+	return problems
+}
+
+// validateTest describes a file and the problems Validate reports for it.
+type validateTest struct {
+	name string
+	file func() *File
+	want []problem
+}
+
+// checkProblems validates each file and compares the problems found.
+func checkProblems(t *testing.T, tests []validateTest) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemsOf(fieldErrors(t, tt.file().Validate()))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	checkProblems(t, []validateTest{
+		{name: "valid", file: twoChannelFile},
+		{name: "long driver", file: func() *File {
+			f := twoChannelFile()
+			f.Driver = strings.Repeat("x", 65)
+			return f
+		}, want: []problem{{-1, "Driver", ErrStringTooLong}}},
+		{name: "duplicate name", file: func() *File {
+			f := &File{}
+			f.AddChannels(
+				&Channel[int16]{Frequency: 1, Name: "A", Data: &[]int16{}},
+				&Channel[int16]{Frequency: 1, Name: "A", Data: &[]int16{}},
+				&Channel[int16]{Frequency: 1, Name: "B", Data: &[]int16{}},
+			)
+			return f
+		}, want: []problem{{1, "Name", ErrDuplicateName}}},
+		{name: "channel fields", file: func() *File {
+			f := &File{}
+			f.AddChannels(
+				&Channel[float32]{Name: strings.Repeat("x", 33), Unit: strings.Repeat("u", 13)},
+				&Channel[float32]{Frequency: 1, Name: "NaN", Data: &[]float32{1, float32(math.NaN()), float32(math.Inf(1))}},
+				&StreamChannel[float32]{Frequency: 1, Name: "Stream"},
+			)
+			return f
+		}, want: []problem{
+			{0, "Name", ErrStringTooLong},
+			{0, "Unit", ErrStringTooLong},
+			{0, "Frequency", ErrInvalidFrequency},
+			{0, "Data", ErrNilData},
+			{1, "Data", ErrNonFiniteValue},
+			{2, "Reader", ErrNilData},
+		}},
+	})
+}
+
+func TestValidateEventComment(t *testing.T) {
+	checkProblems(t, []validateTest{
+		{name: "at the limit", file: func() *File {
+			f := twoChannelFile()
+			f.EventComment = strings.Repeat("x", 1024)
+			return f
+		}},
+		{name: "too long", file: func() *File {
+			f := twoChannelFile()
+			f.EventComment = strings.Repeat("x", 1025)
+			return f
+		}, want: []problem{{-1, "EventComment", ErrStringTooLong}}},
+	})
+}
+
+func TestValidateDuplicateShortName(t *testing.T) {
+	checkProblems(t, []validateTest{
+		{name: "duplicate", file: func() *File {
+			f := &File{}
+			f.AddChannels(
+				&Channel[int16]{Frequency: 1, Name: "A", ShortName: "S", Data: &[]int16{}},
+				&Channel[int16]{Frequency: 1, Name: "B", ShortName: "S", Data: &[]int16{}},
+				&Channel[int16]{Frequency: 1, Name: "C", Data: &[]int16{}},
+				&Channel[int16]{Frequency: 1, Name: "D", Data: &[]int16{}},
+			)
+			return f
+		}, want: []problem{{1, "ShortName", ErrDuplicateShortName}}},
+	})
+}
+
+func TestValidateEmptyName(t *testing.T) {
+	checkProblems(t, []validateTest{
+		{name: "empty name", file: func() *File {
+			f := &File{}
+			f.AddChannels(&Channel[int16]{Frequency: 1, Data: &[]int16{}})
+			return f
+		}, want: []problem{{0, "Name", ErrEmptyName}}},
+		{name: "empty name with AutoName", file: func() *File {
+			f := &File{AutoName: true}
+			f.AddChannels(&Channel[int16]{Frequency: 1, Data: &[]int16{}})
+			return f
+		}},
+	})
+}
+
+func TestCheckUnits(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Unit: "km/h"},
+		&Channel[float32]{Unit: "kmh"},
+		&Channel[float32]{},
+	)
+
+	want := []problem{{1, "Unit", ErrUnknownUnit}}
+	if got := problemsOf(fieldErrors(t, errors.Join(f.CheckUnits()...))); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckFrequencyDivisors(t *testing.T) {
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Frequency: 50},
+		&Channel[float32]{Frequency: 30},
+		&Channel[float32]{Frequency: 0},
+	)
+
+	want := []problem{{1, "Frequency", ErrFrequencyNotDivisor}}
+	if got := problemsOf(fieldErrors(t, errors.Join(f.CheckFrequencyDivisors(100)...))); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckDurations(t *testing.T) {
+	whole := &Channel[int16]{Frequency: 2, Data: &[]int16{1, 2, 3, 4}}
+	fractional := &Channel[int16]{Frequency: 2, Data: &[]int16{1, 2, 3}}
+	zero := &Channel[int16]{Data: &[]int16{1}}
+
+	f := &File{}
+	f.AddChannels(whole, fractional, zero, &StreamChannel[int16]{Frequency: 3})
+
+	want := []problem{{1, "Data", ErrFractionalDuration}}
+	if got := problemsOf(fieldErrors(t, errors.Join(f.CheckDurations()...))); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !whole.HasIntegerDuration() || fractional.HasIntegerDuration() || zero.HasIntegerDuration() {
+		t.Error("HasIntegerDuration disagrees with CheckDurations")
+	}
+}
+
+func TestCheckVehicleWeight(t *testing.T) {
+	for weight, wantErr := range map[uint32]bool{0: false, 1200: false, MaxVehicleWeight: false, MaxVehicleWeight + 1: true} {
+		err := (&File{VehicleWeight: weight}).CheckVehicleWeight()
+		if wantErr != errors.Is(err, ErrImplausibleWeight) {
+			t.Errorf("%d kg: got %v", weight, err)
+		}
+	}
+}
+
+func TestCheckAliasing(t *testing.T) {
+	data := []float32{1, 2, 3, 4}
+	head, tail := data[:3], data[2:]
+	other := []float32{1, 2}
+
+	f := &File{}
+	f.AddChannels(
+		&Channel[float32]{Data: &data},
+		&Channel[float32]{Data: &data},
+		&Channel[float32]{Data: &other},
+		&Channel[float32]{Data: &tail},
+		&Channel[float32]{},
+	)
+	want := []problem{{1, "Data", ErrAliasedData}, {3, "Data", ErrAliasedData}}
+	if got := problemsOf(fieldErrors(t, errors.Join(f.CheckAliasing()...))); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Adjacent slices of the same array do not overlap
+	f = &File{}
+	f.AddChannels(&Channel[float32]{Data: &head}, &Channel[float32]{Data: &other})
+	if errs := f.CheckAliasing(); errs != nil {
+		t.Errorf("got %v", errs)
+	}
+}
+
+func TestWriteStrict(t *testing.T) {
+	f := twoChannelFile()
+	f.Channels[0].(*Channel[float32]).Unit = "kmh"
+	f.Driver = strings.Repeat("x", 65)
+
+	buf := &writeBuffer{}
+	got := problemsOf(fieldErrors(t, f.WriteStrict(buf)))
+	want := []problem{{-1, "Driver", ErrStringTooLong}, {0, "Unit", ErrUnknownUnit}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(buf.data) != 0 {
+		t.Errorf("wrote %d bytes of an invalid file", len(buf.data))
+	}
+
+	valid := twoChannelFile()
+	if err := valid.WriteStrict(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.data, writeBytes(t, valid)) {
+		t.Error("WriteStrict and WriteTo output differ")
+	}
+}
+
+func TestWriteStrictSizeMismatch(t *testing.T) {
+	// A destination holding more data than the file is reported
+	dirty := &writeBuffer{data: make([]byte, 10000)}
+	if err := twoChannelFile().WriteStrict(dirty); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("got %v, want ErrSizeMismatch", err)
+	}
+}
+
+func TestTruncateComment(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{s: "short", want: 5},
+		{s: strings.Repeat("x", 1024), want: 1024},
+		{s: strings.Repeat("x", 1023) + "é", want: 1023},
+		{s: strings.Repeat("€", 400), want: 1023},
+	}
+
+	for _, tt := range tests {
+		got := TruncateComment(tt.s)
+		if len(got) != tt.want || !strings.HasPrefix(tt.s, got) {
+			t.Errorf("%d bytes: got %d bytes, want %d", len(tt.s), len(got), tt.want)
+		}
+	}
+}
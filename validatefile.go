@@ -0,0 +1,146 @@
+package motecldparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+var (
+	// ErrInvalidPointer is reported when a pointer in the file is outside the file.
+	ErrInvalidPointer = errors.New("motecldparser: pointer outside the file")
+
+	// ErrBrokenChannelList is reported when the links between channel metadata
+	// blocks are inconsistent.
+	ErrBrokenChannelList = errors.New("motecldparser: broken channel list")
+
+	// ErrChannelCountMismatch is returned when the number of channels in the
+	// channel metadata list differs from the count declared in the header.
+	ErrChannelCountMismatch = errors.New("motecldparser: channel count mismatch")
+)
+
+// ValidateFile checks the structure of the LD file at path without decoding
+// its channel data.
+//
+// Only the header and the channel metadata are read. The following checks
+// are performed:
+//   - the file starts with the LD marker
+//   - the event, venue, vehicle and channel metadata pointers and every
+//     channel data region lie within the file
+//   - the channel metadata forms a linked list, whose length matches the
+//     channel count in the header (see ErrChannelCountMismatch); as with
+//     Read, the header may point to any block of the list
+//   - channel data regions do not overlap the metadata or each other
+//
+// Every pointer is checked against the size of the file before the block it
+// points to is read, so truncated files are reported with ErrInvalidPointer.
+// Checking goes on past a problem whenever the rest of the structure can
+// still be reached; a missing header or marker stops it, and a broken channel
+// list stops the channel checks.
+//
+// This gives a fast answer to "is this file sane" even for very large files.
+// Returns nil if the file is valid, or all problems found joined with
+// errors.Join.
+func ValidateFile(path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	stat, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	return checkStructure(fd, stat.Size())
+}
+
+// checkStructure verifies the structure of a file of the given size, reading
+// each block only once its pointer is known to be in bounds.
+func checkStructure(r io.ReaderAt, size int64) error {
+	var errs []error
+
+	inBounds := func(name string, pointer uint32, length int64) bool {
+		if int64(pointer)+length > size {
+			errs = append(errs, fmt.Errorf("%w: %s at %d, length %d, file size %d", ErrInvalidPointer, name, pointer, length, size))
+			return false
+		}
+		return true
+	}
+
+	// read reads a block in bounds, recording read errors
+	read := func(name string, pointer uint32, length int64, data any) bool {
+		if !inBounds(name, pointer, length) {
+			return false
+		}
+		if err := readAt(r, int64(pointer), data); err != nil {
+			errs = append(errs, fmt.Errorf("read %s: %w", name, err))
+			return false
+		}
+		return true
+	}
+
+	var head ldfile.LdFileHead
+	if !read("header", 0, headSize, &head) {
+		return errors.Join(errs...)
+	}
+	if head.LDMarker != 0x40 {
+		return ErrInvalidMarker
+	}
+
+	var event ldfile.LdFileEvent
+	if head.EventPointer != 0 && read("event", head.EventPointer, eventSize, &event) {
+		var venue ldfile.LdFileVenue
+		if event.VenuePointer != 0 && read("venue", uint32(event.VenuePointer), venueSize, &venue) {
+			var vehicle ldfile.LdFileVehicle
+			if venue.VehiclePointer != 0 {
+				read("vehicle", uint32(venue.VehiclePointer), vehicleSize, &vehicle)
+			}
+		}
+	}
+	inBounds("channel data", head.ChannelsDataPointer, 0)
+
+	// Read the channel list the way Read does, with every metadata pointer
+	// checked against the size of the file
+	bounded := &boundedReader{r: r, size: size}
+	metas, metaPointers, err := readChannelList(bounded, head)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	metaSize := detectMetaSize(metas, metaPointers)
+	for i := range metas {
+		inBounds(fmt.Sprintf("channel %d metadata", i), metaPointers[i], int64(metaSize))
+	}
+	if err := checkDataBounds(bounded, metas); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkOverlaps(metas, metaPointers, metaSize); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// boundedReader reads from a source of known size, reporting reads past the
+// end as ErrInvalidPointer.
+type boundedReader struct {
+	r    io.ReaderAt
+	size int64
+}
+
+func (b *boundedReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > b.size {
+		return 0, fmt.Errorf("%w: block at %d, length %d, file size %d", ErrInvalidPointer, off, len(p), b.size)
+	}
+	return b.r.ReadAt(p, off)
+}
+
+// Size returns the size of the source, so checkDataBounds does not read it.
+func (b *boundedReader) Size() int64 {
+	return b.size
+}
@@ -0,0 +1,109 @@
+package motecldparser
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch func(f *File, data []byte) []byte
+		want  []error
+	}{
+		{
+			name:  "valid",
+			patch: func(f *File, data []byte) []byte { return data },
+		},
+		{
+			name:  "truncated before the venue",
+			patch: func(f *File, data []byte) []byte { return data[:3000] },
+			want:  []error{ErrInvalidPointer},
+		},
+		{
+			name:  "truncated in the header",
+			patch: func(f *File, data []byte) []byte { return data[:100] },
+			want:  []error{ErrInvalidPointer},
+		},
+		{
+			name:  "truncated data",
+			patch: func(f *File, data []byte) []byte { return data[:len(data)-1] },
+			want:  []error{ErrInvalidPointer},
+		},
+		{
+			name: "wrong channel count",
+			patch: func(f *File, data []byte) []byte {
+				binary.LittleEndian.PutUint32(data[86:], 3)
+				return data
+			},
+			want: []error{ErrChannelCountMismatch},
+		},
+		{
+			name: "overlap and event outside the file",
+			patch: func(f *File, data []byte) []byte {
+				patchMeta(t, f, data, 1, metaDataPointerOffset, uint32(f.LastWritePlan.Channels[0].DataPointer))
+				binary.LittleEndian.PutUint32(data[36:], uint32(len(data)))
+				return data
+			},
+			want: []error{ErrOverlappingData, ErrInvalidPointer},
+		},
+		{
+			name: "looping list",
+			patch: func(f *File, data []byte) []byte {
+				binary.LittleEndian.PutUint32(data[86:], 3)
+				patchMeta(t, f, data, 1, 4, uint32(f.LastWritePlan.Channels[0].MetaPointer))
+				return data
+			},
+			want: []error{ErrBrokenChannelList},
+		},
+		{
+			name: "metadata pointer outside the file",
+			patch: func(f *File, data []byte) []byte {
+				patchMeta(t, f, data, 0, 4, uint32(len(data)))
+				return data
+			},
+			want: []error{ErrInvalidPointer},
+		},
+		{
+			name: "header points mid-list",
+			patch: func(f *File, data []byte) []byte {
+				return relayout(t, threeChannelFile(), ldfile.ChannelMetaSize, []int{1, 0, 2}, []int{0, 1, 2})
+			},
+		},
+		{
+			name: "invalid marker",
+			patch: func(f *File, data []byte) []byte {
+				data[0] = 0
+				return data
+			},
+			want: []error{ErrInvalidMarker},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := twoChannelFile()
+			data := tt.patch(f, writeBytes(t, f))
+
+			path := filepath.Join(t.TempDir(), "file.ld")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := ValidateFile(path)
+			if len(tt.want) == 0 && err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("got %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package motecldparser
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+// Variant identifies the channel metadata layout of a file.
+type Variant int
+
+const (
+	// VariantACC is the layout with 124-byte channel metadata blocks, written
+	// by this package and by Assetto Corsa Competizione.
+	VariantACC Variant = iota
+
+	// VariantActi is the layout with 116-byte channel metadata blocks.
+	VariantActi
+)
+
+// String returns the name of the variant.
+func (v Variant) String() string {
+	switch v {
+	case VariantACC:
+		return "ACC"
+	case VariantActi:
+		return "acti"
+	default:
+		return fmt.Sprintf("Variant(%d)", int(v))
+	}
+}
+
+// DetectVariant reports the channel metadata layout of a file without
+// parsing it fully.
+//
+// Only the header and the channel metadata blocks are read. The DeviceType
+// and DeviceVersion header fields do not reliably identify the layout, so it
+// is detected like Read does, from the smallest distance between metadata
+// blocks. Files without channels are reported as VariantACC.
+//
+// Returns ErrInvalidMarker if r does not hold an LD file, and
+// ErrBrokenChannelList or ErrChannelCountMismatch if its channel list is
+// inconsistent.
+func DetectVariant(r io.ReaderAt) (Variant, error) {
+	var head ldfile.LdFileHead
+	if err := readAt(r, 0, &head); err != nil {
+		return VariantACC, fmt.Errorf("read header: %w", err)
+	}
+
+	if head.LDMarker != 0x40 {
+		return VariantACC, ErrInvalidMarker
+	}
+
+	metas, metaPointers, err := readChannelList(r, head)
+	if err != nil {
+		return VariantACC, err
+	}
+
+	if detectMetaSize(metas, metaPointers) == ldfile.ChannelMetaSizeActi {
+		return VariantActi, nil
+	}
+	return VariantACC, nil
+}
@@ -0,0 +1,110 @@
+package motecldparser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/riccardotornesello/motecldparser/ldfile"
+)
+
+func TestDetectVariant(t *testing.T) {
+	oneChannel := func() *File {
+		f := &File{}
+		f.AddChannels(&Channel[float32]{Frequency: 10, Name: "Speed", Data: &[]float32{1}})
+		return f
+	}
+
+	tests := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		want    Variant
+		wantErr error
+	}{
+		{name: "ACC", data: func(t *testing.T) []byte { return writeBytes(t, threeChannelFile()) }, want: VariantACC},
+		{name: "ACC single channel", data: func(t *testing.T) []byte { return writeBytes(t, oneChannel()) }, want: VariantACC},
+		{name: "no channels", data: func(t *testing.T) []byte { return writeBytes(t, &File{}) }, want: VariantACC},
+		{
+			name: "acti",
+			data: func(t *testing.T) []byte {
+				return relayout(t, threeChannelFile(), ldfile.ChannelMetaSizeActi, []int{0, 1, 2}, []int{0, 1, 2})
+			},
+			want: VariantActi,
+		},
+		{
+			name: "acti single channel",
+			data: func(t *testing.T) []byte {
+				return relayout(t, oneChannel(), ldfile.ChannelMetaSizeActi, []int{0}, []int{0})
+			},
+			want: VariantActi,
+		},
+		{
+			name: "acti metadata reversed",
+			data: func(t *testing.T) []byte {
+				return relayout(t, threeChannelFile(), ldfile.ChannelMetaSizeActi, []int{2, 1, 0}, []int{0, 1, 2})
+			},
+			want: VariantActi,
+		},
+		{
+			name: "invalid marker",
+			data: func(t *testing.T) []byte {
+				data := writeBytes(t, threeChannelFile())
+				data[0] = 0
+				return data
+			},
+			wantErr: ErrInvalidMarker,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectVariant(bytes.NewReader(tt.data(t)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadActi(t *testing.T) {
+	tests := []struct {
+		name      string
+		metaOrder []int
+		dataOrder []int
+	}{
+		{name: "in order", metaOrder: []int{0, 1, 2}, dataOrder: []int{0, 1, 2}},
+		{name: "out of order", metaOrder: []int{1, 2, 0}, dataOrder: []int{2, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := relayout(t, threeChannelFile(), ldfile.ChannelMetaSizeActi, tt.metaOrder, tt.dataOrder)
+
+			read, err := Read(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkThreeChannels(t, read)
+
+			lf, err := OpenLazy(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]float64, 3)
+			if n, err := lf.ChannelInto("Speed", buf); err != nil || n != 3 || buf[2] != 3.5 {
+				t.Errorf("lazy Speed = %v, %d samples, error %v", buf, n, err)
+			}
+		})
+	}
+}
+
+func TestVariantString(t *testing.T) {
+	for v, want := range map[Variant]string{VariantACC: "ACC", VariantActi: "acti", Variant(7): "Variant(7)"} {
+		if got := v.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}